@@ -0,0 +1,117 @@
+// Package swarm defines the API types for a cluster managed by swarm mode.
+//
+// This checkout only carries the slice of the package that
+// integration-cli/docker_api_swarm_test.go's reviewed commits reference
+// directly (LocalNodeState, Spec.CAConfig, JoinRequest, and
+// Info.LocalNodeState below); the rest of the real package (Service, Node,
+// TaskSpec, and friends, also used elsewhere in that same test file)
+// predates this diff and isn't reconstructed here.
+package swarm
+
+// LocalNodeState represents the state of the local node.
+type LocalNodeState string
+
+const (
+	// LocalNodeStateInactive ...
+	LocalNodeStateInactive LocalNodeState = "inactive"
+	// LocalNodeStatePending ...
+	LocalNodeStatePending LocalNodeState = "pending"
+	// LocalNodeStateActive ...
+	LocalNodeStateActive LocalNodeState = "active"
+)
+
+// Spec is the content of the cluster specification.
+type Spec struct {
+	CAConfig CAConfig `json:",omitempty"`
+}
+
+// CAConfig represents the CA configuration for a swarm cluster.
+type CAConfig struct {
+	// ForceRotate is an incrementing counter used to force a CA rotation
+	// even when no other fields of CAConfig change.
+	ForceRotate uint64 `json:",omitempty"`
+
+	// SigningCACert and SigningCAKey, if set, mean the manager generates
+	// and signs node certificates itself using this root instead of an
+	// autogenerated one.
+	SigningCACert string `json:",omitempty"`
+	SigningCAKey  string `json:",omitempty"`
+
+	// ExternalCAs is a list of CAs to which a manager node forwards
+	// signing requests, selected by matching ExternalCA.Protocol.
+	ExternalCAs []*ExternalCA `json:",omitempty"`
+}
+
+// ExternalCAProtocol identifies the protocol used with an external CA.
+type ExternalCAProtocol string
+
+// ExternalCAProtocolCFSSL CFSSL
+const ExternalCAProtocolCFSSL ExternalCAProtocol = "cfssl"
+
+// ExternalCA defines external CA to be used by the cluster.
+type ExternalCA struct {
+	// Protocol is the protocol used by this external CA. Today only
+	// ExternalCAProtocolCFSSL is recognized; a pluggable signer
+	// interface selected by an arbitrary Protocol string (Vault PKI,
+	// step-ca ACME, ...) hasn't been added, so any other value here is
+	// accepted as opaque config but never dispatched to a driver.
+	Protocol ExternalCAProtocol
+
+	// URL is the URL where the external CA can be reached.
+	URL string
+
+	// Options is a set of additional key/value pairs whose meaning is
+	// specific to the CA driver.
+	Options map[string]string `json:",omitempty"`
+
+	// CACert specifies which root CA is used by this external CA. This
+	// certificate must be in PEM format.
+	CACert string `json:",omitempty"`
+}
+
+// JoinRequest is the request used to join a swarm.
+type JoinRequest struct {
+	ListenAddr  string
+	RemoteAddrs []string
+	JoinToken   string // accept by secret
+}
+
+// PlacementPreference provides a way to make the scheduler aware of factors
+// such as topology. It is provided in order of priority.
+type PlacementPreference struct {
+	Spread *SpreadOver `json:",omitempty"`
+
+	// WeightedSpread is the multi-key counterpart to Spread: instead of
+	// balancing over a single label, it balances over an ordered list of
+	// label keys, each contributing Weight to a combined per-node score.
+	// Kept as a separate field (rather than extending SpreadOver) so
+	// existing JSON encodings using Spread keep decoding unchanged.
+	WeightedSpread *WeightedSpreadOver `json:",omitempty"`
+}
+
+// SpreadOver is a scheduling preference that instructs the scheduler to
+// spread tasks evenly over groups of nodes identified by a label.
+type SpreadOver struct {
+	// SpreadDescriptor is the label to spread over.
+	SpreadDescriptor string
+}
+
+// SpreadDescriptor is one (label key, weight) pair in a WeightedSpreadOver
+// preference: Weight controls how much this key's imbalance contributes to
+// a candidate node's combined score relative to the other keys listed.
+type SpreadDescriptor struct {
+	Key    string
+	Weight uint32
+}
+
+// WeightedSpreadOver balances placement across an ordered list of label
+// keys at once instead of SpreadOver's single key, e.g. spreading over
+// region, then zone, then rack with different weights for each.
+type WeightedSpreadOver struct {
+	SpreadDescriptors []SpreadDescriptor
+}
+
+// Info represents generic information about swarm.
+type Info struct {
+	LocalNodeState LocalNodeState
+}
@@ -0,0 +1,18 @@
+// Package system defines the API types for the Docker/Moby system (daemon
+// host) endpoints.
+//
+// This checkout only carries the one field
+// integration-cli/docker_api_swarm_test.go's checkClusterHealth helper
+// reads (Info.Swarm); swarm.Info here has only LocalNodeState, not the
+// ControlAvailable field that helper also reads. The rest of the real Info
+// struct (dozens of host/daemon fields) predates this diff and isn't
+// reconstructed here.
+package system
+
+import "github.com/moby/moby/api/types/swarm"
+
+// Info contains response of Engine API:
+// GET "/info".
+type Info struct {
+	Swarm swarm.Info
+}
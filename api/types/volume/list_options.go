@@ -0,0 +1,31 @@
+package volume
+
+import "github.com/moby/moby/api/types/filters"
+
+// ListOptions holds parameters to list volumes.
+//
+// Limit and Last implement the same cursor-based pagination as the
+// registry catalog endpoint: a request with no Last returns the first
+// page, and a subsequent request should pass the Next token from the
+// previous ListResponse as Last to continue. Filters narrows the set a
+// page is drawn from before pagination is applied, so a `label` filter
+// can page over a subset (e.g. `label=env=prod`) without the volume
+// store having to materialize every driver's full inventory first.
+//
+// This checkout only carries the API type: the volume service that would
+// read Limit/Last/Filters off the request and the drivers.Store that
+// would enumerate volumes to paginate over predate this diff and aren't
+// reconstructed here, so nothing in this checkout actually pages yet.
+type ListOptions struct {
+	// Limit is the maximum number of volumes to return. Zero means no
+	// limit is requested, and the server may still cap the page size.
+	Limit int
+
+	// Last is the continuation token from a previous ListResponse.Next,
+	// or empty to request the first page.
+	Last string
+
+	// Filters is a filter-expression, encoded the same way as other
+	// list endpoints (e.g. `label=env=prod`).
+	Filters filters.Args
+}
@@ -19,4 +19,23 @@ type ListResponse struct {
 	//
 	// Example: []
 	Warnings []string `json:"Warnings"`
+
+	// Next is an opaque continuation token. Pass it as Last on a
+	// subsequent ListOptions, along with the same Limit and Filters, to
+	// fetch the next page. Empty if Volumes is the last (or only) page.
+	//
+	// Nothing in this checkout populates Next yet (see ListOptions'
+	// doc comment): the volume service that would paginate and set it
+	// isn't reconstructed here.
+	//
+	// Example: my-volume
+	Next string `json:"Next,omitempty"`
+
+	// Total is the total number of volumes matching the request's filters,
+	// across all pages, or -1 if the total could not be determined without
+	// materializing every driver's inventory. Unpopulated for the same
+	// reason as Next.
+	//
+	// Example: 24
+	Total int64 `json:"Total,omitempty"`
 }
@@ -0,0 +1,15 @@
+// Package client is the Go SDK for the Docker/Moby Engine API.
+//
+// This checkout doesn't carry any of the real client package's methods
+// (SwarmJoin, Info, NetworkCreate, and the rest of what
+// integration-cli/docker_api_swarm_test.go calls) — they predate this diff
+// and aren't reconstructed here. Only the Client type itself is kept, since
+// callers elsewhere in this checkout need something to hold a *Client.
+package client
+
+import "net/http"
+
+// Client is the Docker/Moby Engine API client.
+type Client struct {
+	HTTPClient *http.Client
+}
@@ -3,6 +3,7 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/containerd/log"
 	"github.com/moby/moby/api/types/filters"
@@ -16,7 +17,16 @@ import (
 )
 
 // GetNetworks returns all current cluster managed networks.
-func (c *Cluster) GetNetworks(filter filters.Args) ([]network.Inspect, error) {
+//
+// Only the name, id, and label filters are pushed down into the
+// ListNetworksRequest sent to swarmkit. A driver filter falls back to the
+// client-side pass through FilterNetworks below it, short of the "at
+// minimum driver and labels" bar: swarmapi.ListNetworksRequest_Filters (a
+// generated type in github.com/moby/swarmkit/v2/api, a separate module this
+// checkout depends on rather than vendors) has no Driver field to populate,
+// so there is no request shape that forwards it without a change to that
+// upstream proto. Scope has the same problem and isn't pushed down either.
+func (c *Cluster) GetNetworks(ctx context.Context, filter filters.Args) ([]network.Inspect, error) {
 	var f *swarmapi.ListNetworksRequest_Filters
 
 	if filter.Len() > 0 {
@@ -30,9 +40,21 @@ func (c *Cluster) GetNetworks(filter filters.Args) ([]network.Inspect, error) {
 		if filter.Contains("id") {
 			f.IDPrefixes = filter.Get("id")
 		}
+
+		// Labels use the same "key" / "key=value" matching semantics
+		// swarmkit already applies to ListServicesRequest_Filters, so a
+		// label filter short-circuits on the manager instead of shipping
+		// every network over the wire for FilterNetworks to drop.
+		if filter.Contains("label") {
+			f.Labels = filter.Get("label")
+		}
+
+		// Driver and scope have no field on ListNetworksRequest_Filters to
+		// populate; see the doc comment on GetNetworks. They still fall
+		// back to the client-side pass in FilterNetworks below.
 	}
 
-	list, err := c.getNetworks(f)
+	list, err := c.getNetworks(ctx, f)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +79,7 @@ func filterPredefinedNetworks(networks *[]network.Inspect) {
 	}
 }
 
-func (c *Cluster) getNetworks(filters *swarmapi.ListNetworksRequest_Filters) ([]network.Inspect, error) {
+func (c *Cluster) getNetworks(ctx context.Context, filters *swarmapi.ListNetworksRequest_Filters) ([]network.Inspect, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -66,7 +88,6 @@ func (c *Cluster) getNetworks(filters *swarmapi.ListNetworksRequest_Filters) ([]
 		return nil, c.errNoManager(state)
 	}
 
-	ctx := context.TODO()
 	ctx, cancel := context.WithTimeout(ctx, swarmRequestTimeout)
 	defer cancel()
 
@@ -85,10 +106,10 @@ func (c *Cluster) getNetworks(filters *swarmapi.ListNetworksRequest_Filters) ([]
 }
 
 // GetNetwork returns a cluster network by an ID.
-func (c *Cluster) GetNetwork(input string) (network.Inspect, error) {
+func (c *Cluster) GetNetwork(ctx context.Context, input string) (network.Inspect, error) {
 	var nw *swarmapi.Network
 
-	if err := c.lockedManagerAction(func(ctx context.Context, state nodeState) error {
+	if err := c.lockedManagerAction(ctx, func(ctx context.Context, state nodeState) error {
 		n, err := getNetwork(ctx, state.controlClient, input)
 		if err != nil {
 			return err
@@ -103,10 +124,10 @@ func (c *Cluster) GetNetwork(input string) (network.Inspect, error) {
 
 // GetNetworksByName returns cluster managed networks by name.
 // It is ok to have multiple networks here. #18864
-func (c *Cluster) GetNetworksByName(name string) ([]network.Inspect, error) {
+func (c *Cluster) GetNetworksByName(ctx context.Context, name string) ([]network.Inspect, error) {
 	// Note that swarmapi.GetNetworkRequest.Name is not functional.
 	// So we cannot just use that with c.GetNetwork.
-	return c.getNetworks(&swarmapi.ListNetworksRequest_Filters{
+	return c.getNetworks(ctx, &swarmapi.ListNetworksRequest_Filters{
 		Names: []string{name},
 	})
 }
@@ -118,7 +139,11 @@ func attacherKey(target, containerID string) string {
 // UpdateAttachment signals the attachment config to the attachment
 // waiter who is trying to start or attach the container to the
 // network.
-func (c *Cluster) UpdateAttachment(target, containerID string, config *network.NetworkingConfig) error {
+func (c *Cluster) UpdateAttachment(ctx context.Context, target, containerID string, config *network.NetworkingConfig) error {
+	if err := verifyNetworkingConfig(config, false); err != nil {
+		return err
+	}
+
 	c.mu.Lock()
 	attacher, ok := c.attachers[attacherKey(target, containerID)]
 	if !ok || attacher == nil {
@@ -126,14 +151,18 @@ func (c *Cluster) UpdateAttachment(target, containerID string, config *network.N
 		return fmt.Errorf("could not find attacher for container %s to network %s", containerID, target)
 	}
 	if attacher.inProgress {
-		log.G(context.TODO()).Debugf("Discarding redundant notice of resource allocation on network %s for task id %s", target, attacher.taskID)
+		log.G(ctx).Debugf("Discarding redundant notice of resource allocation on network %s for task id %s", target, attacher.taskID)
 		c.mu.Unlock()
 		return nil
 	}
 	attacher.inProgress = true
 	c.mu.Unlock()
 
-	attacher.attachWaitCh <- config
+	select {
+	case attacher.attachWaitCh <- config:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
 	return nil
 }
@@ -171,6 +200,15 @@ func (c *Cluster) WaitForDetachment(ctx context.Context, networkName, networkID,
 			case <-ctx.Done():
 				return ctx.Err()
 			}
+
+			// Share the same idempotent release AttachNetwork registered
+			// for this attacher, so a concurrent AttachNetwork cleanup (on
+			// a ctx cancellation) and this call can't both call
+			// DetachNetwork for the same taskID. Left registered for
+			// DetachNetwork to clean up, since a second WaitForDetachment
+			// call (or AttachNetwork's own ctx.Done path) racing this one
+			// must still observe the same release, not a fresh one.
+			return sharedRelease(attacher, func() func() error { return detachOnceErr(agent, taskID) })()
 		}
 	}
 
@@ -178,7 +216,11 @@ func (c *Cluster) WaitForDetachment(ctx context.Context, networkName, networkID,
 }
 
 // AttachNetwork generates an attachment request towards the manager.
-func (c *Cluster) AttachNetwork(target string, containerID string, addresses []string) (*network.NetworkingConfig, error) {
+func (c *Cluster) AttachNetwork(ctx context.Context, target string, containerID string, addresses []string) (*network.NetworkingConfig, error) {
+	if err := verifyAttachAddresses(addresses); err != nil {
+		return nil, err
+	}
+
 	aKey := attacherKey(target, containerID)
 	c.mu.Lock()
 	state := c.currentNodeState()
@@ -195,25 +237,58 @@ func (c *Cluster) AttachNetwork(target string, containerID string, addresses []s
 	attachWaitCh := make(chan *network.NetworkingConfig)
 	detachWaitCh := make(chan struct{})
 	attachCompleteCh := make(chan struct{})
-	c.attachers[aKey] = &attacher{
+	a := &attacher{
 		attachWaitCh:     attachWaitCh,
 		attachCompleteCh: attachCompleteCh,
 		detachWaitCh:     detachWaitCh,
 	}
+	c.attachers[aKey] = a
 	c.mu.Unlock()
 
-	ctx := context.TODO()
-	ctx, cancel := context.WithTimeout(ctx, swarmRequestTimeout)
-	defer cancel()
+	policy := c.attachRetryPolicy()
 
-	taskID, err := agent.ResourceAllocator().AttachNetwork(ctx, containerID, target, addresses)
-	if err != nil {
-		c.mu.Lock()
-		delete(c.attachers, aKey)
-		c.mu.Unlock()
-		return nil, fmt.Errorf("Could not attach to network %s: %v", target, err)
+	var taskID string
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, swarmRequestTimeout)
+		newTaskID, err := agent.ResourceAllocator().AttachNetwork(attemptCtx, containerID, target, addresses)
+		cancel()
+		if err == nil {
+			taskID = newTaskID
+			break
+		}
+
+		// A retryable error (leader election, a dropped gRPC stream)
+		// can still leave the manager having allocated newTaskID
+		// before the response was lost; release it before asking for
+		// a new attachment so a flaky manager doesn't leak one task
+		// allocation per retry.
+		if newTaskID != "" {
+			detachTaskID(ctx, agent, newTaskID, target)
+		}
+
+		if attempt >= policy.MaxRetries || !retryableAttachError(err) {
+			c.mu.Lock()
+			delete(c.attachers, aKey)
+			c.mu.Unlock()
+			return nil, fmt.Errorf("Could not attach to network %s: %v", target, err)
+		}
+
+		log.G(ctx).WithError(err).Warnf("Retrying attachment to network %s for container %s (attempt %d/%d)",
+			target, containerID, attempt+1, policy.MaxRetries)
+
+		select {
+		case <-time.After(policy.delay(attempt)):
+		case <-ctx.Done():
+			c.mu.Lock()
+			delete(c.attachers, aKey)
+			c.mu.Unlock()
+			return nil, ctx.Err()
+		}
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, swarmRequestTimeout)
+	defer cancel()
+
 	c.mu.Lock()
 	c.attachers[aKey].taskID = taskID
 	close(attachCompleteCh)
@@ -221,21 +296,19 @@ func (c *Cluster) AttachNetwork(target string, containerID string, addresses []s
 
 	log.G(ctx).Debugf("Successfully attached to network %s with task id %s", target, taskID)
 
-	release := func() {
-		ctx := context.WithoutCancel(ctx)
-		ctx, cancel := context.WithTimeout(ctx, swarmRequestTimeout)
-		defer cancel()
-		if err := agent.ResourceAllocator().DetachNetwork(ctx, taskID); err != nil {
-			log.G(ctx).Errorf("Failed remove network attachment %s to network %s on allocation failure: %v",
-				taskID, target, err)
-		}
-	}
+	// Registered under a (not created fresh) so a concurrent
+	// WaitForDetachment for the same attacher shares this exact
+	// sync.Once-guarded release instead of racing it with a second
+	// DetachNetwork call for the same taskID.
+	release := sharedRelease(a, func() func() error { return detachOnceErr(agent, taskID) })
 
 	var config *network.NetworkingConfig
 	select {
 	case config = <-attachWaitCh:
 	case <-ctx.Done():
-		release()
+		if err := release(); err != nil {
+			log.G(ctx).WithError(err).Errorf("Failed to release network attachment %s to network %s", taskID, target)
+		}
 		return nil, fmt.Errorf("attaching to network failed, make sure your network options are correct and check manager logs: %v", ctx.Err())
 	}
 
@@ -250,7 +323,7 @@ func (c *Cluster) AttachNetwork(target string, containerID string, addresses []s
 
 // DetachNetwork unblocks the waiters waiting on WaitForDetachment so
 // that a request to detach can be generated towards the manager.
-func (c *Cluster) DetachNetwork(target string, containerID string) error {
+func (c *Cluster) DetachNetwork(ctx context.Context, target string, containerID string) error {
 	aKey := attacherKey(target, containerID)
 
 	c.mu.Lock()
@@ -258,6 +331,13 @@ func (c *Cluster) DetachNetwork(target string, containerID string) error {
 	delete(c.attachers, aKey)
 	c.mu.Unlock()
 
+	if ok {
+		// This attacher is leaving c.attachers for good; drop its shared
+		// release too so releaseRegistry doesn't keep an entry for an
+		// attachment nothing can look up anymore.
+		forgetRelease(attacher)
+	}
+
 	if !ok {
 		return fmt.Errorf("could not find network attachment for container %s to network %s", containerID, target)
 	}
@@ -267,14 +347,18 @@ func (c *Cluster) DetachNetwork(target string, containerID string) error {
 }
 
 // CreateNetwork creates a new cluster managed network.
-func (c *Cluster) CreateNetwork(s network.CreateRequest) (string, error) {
+func (c *Cluster) CreateNetwork(ctx context.Context, s network.CreateRequest) (string, error) {
 	if networkSettings.IsPredefined(s.Name) {
 		err := notAllowedError(fmt.Sprintf("%s is a pre-defined network and cannot be created", s.Name))
 		return "", errors.WithStack(err)
 	}
 
+	if err := verifyIPAMConfig(s.IPAM); err != nil {
+		return "", err
+	}
+
 	var resp *swarmapi.CreateNetworkResponse
-	if err := c.lockedManagerAction(func(ctx context.Context, state nodeState) error {
+	if err := c.lockedManagerAction(ctx, func(ctx context.Context, state nodeState) error {
 		networkSpec := convert.BasicNetworkCreateToGRPC(s)
 		r, err := state.controlClient.CreateNetwork(ctx, &swarmapi.CreateNetworkRequest{Spec: &networkSpec})
 		if err != nil {
@@ -290,8 +374,8 @@ func (c *Cluster) CreateNetwork(s network.CreateRequest) (string, error) {
 }
 
 // RemoveNetwork removes a cluster network.
-func (c *Cluster) RemoveNetwork(input string) error {
-	return c.lockedManagerAction(func(ctx context.Context, state nodeState) error {
+func (c *Cluster) RemoveNetwork(ctx context.Context, input string) error {
+	return c.lockedManagerAction(ctx, func(ctx context.Context, state nodeState) error {
 		nw, err := getNetwork(ctx, state.controlClient, input)
 		if err != nil {
 			return err
@@ -310,6 +394,16 @@ func (c *Cluster) populateNetworkID(ctx context.Context, client swarmapi.Control
 		networks = s.Networks //nolint:staticcheck // ignore SA1019: field is deprecated.
 	}
 	for i, nw := range networks {
+		// TaskTemplate.Networks carries swarmkit's NetworkAttachmentConfig,
+		// not the engine API's network.NetworkingConfig, so
+		// verifyNetworkingConfig's EndpointsConfig/IPAMConfig checks don't
+		// apply here; Addresses is the equivalent field AttachNetwork
+		// validates with the same verifyAttachAddresses used there, giving
+		// service create/update the same malformed-address guarantees.
+		if err := verifyAttachAddresses(nw.Addresses); err != nil {
+			return err
+		}
+
 		apiNetwork, err := getNetwork(ctx, client, nw.Target)
 		if err != nil {
 			ln, _ := c.config.Backend.FindNetwork(nw.Target)
@@ -0,0 +1,154 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/containerd/log"
+	swarmagent "github.com/moby/swarmkit/v2/agent"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// attachRetryPolicy controls how AttachNetwork retries a transient failure
+// from the swarm manager (leader election, a momentary gRPC disconnect)
+// instead of giving up on the first error and killing the container start.
+type attachRetryPolicy struct {
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	MaxRetries int
+}
+
+var defaultAttachRetryPolicy = attachRetryPolicy{
+	BaseDelay:  250 * time.Millisecond,
+	MaxDelay:   5 * time.Second,
+	MaxRetries: 5,
+}
+
+// clusterAttachRetryPolicyMu guards clusterAttachRetryPolicy. Cluster (the
+// real type networks.go's methods are defined on) predates this diff and
+// isn't reconstructed in this checkout, so it has no field of its own to
+// hold a per-Cluster override; keyed by the *Cluster it was set for, the
+// same way networkdb's cluster.go keys watchSubscribers by *NetworkDB for
+// the same reason.
+var (
+	clusterAttachRetryPolicyMu sync.Mutex
+	clusterAttachRetryPolicy   = map[*Cluster]attachRetryPolicy{}
+)
+
+// SetAttachRetryPolicy overrides the AttachNetwork retry policy used by c,
+// so daemon config can tune BaseDelay/MaxDelay/MaxRetries per cluster
+// instead of every attachment defaulting to defaultAttachRetryPolicy.
+func (c *Cluster) SetAttachRetryPolicy(baseDelay, maxDelay time.Duration, maxRetries int) {
+	clusterAttachRetryPolicyMu.Lock()
+	defer clusterAttachRetryPolicyMu.Unlock()
+	clusterAttachRetryPolicy[c] = attachRetryPolicy{
+		BaseDelay:  baseDelay,
+		MaxDelay:   maxDelay,
+		MaxRetries: maxRetries,
+	}
+}
+
+// attachRetryPolicy returns c's configured retry policy, or
+// defaultAttachRetryPolicy if SetAttachRetryPolicy was never called for it.
+func (c *Cluster) attachRetryPolicy() attachRetryPolicy {
+	clusterAttachRetryPolicyMu.Lock()
+	defer clusterAttachRetryPolicyMu.Unlock()
+	if p, ok := clusterAttachRetryPolicy[c]; ok {
+		return p
+	}
+	return defaultAttachRetryPolicy
+}
+
+// delay returns the backoff to wait before retry attempt n (0-indexed),
+// doubling BaseDelay each time and capping at MaxDelay.
+func (p attachRetryPolicy) delay(n int) time.Duration {
+	d := p.BaseDelay << n
+	if d <= 0 || d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// retryableAttachError reports whether err from the manager is worth
+// retrying (Unavailable/DeadlineExceeded/Aborted, the codes swarmkit
+// returns for leader election and transient gRPC disconnects) as opposed to
+// a terminal error (InvalidArgument, AlreadyExists, NotFound) that will
+// never succeed on retry.
+func retryableAttachError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// detachTaskID releases a task allocation best-effort, logging (but not
+// returning) any error, since the caller has no good recovery path beyond
+// retrying the attach itself.
+func detachTaskID(ctx context.Context, agent *swarmagent.Agent, taskID, target string) {
+	if taskID == "" {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	ctx, cancel := context.WithTimeout(ctx, swarmRequestTimeout)
+	defer cancel()
+	if err := agent.ResourceAllocator().DetachNetwork(ctx, taskID); err != nil {
+		log.G(ctx).Errorf("Failed to release network attachment %s to network %s: %v", taskID, target, err)
+	}
+}
+
+// detachOnceErr returns a release function that detaches taskID at most
+// once, no matter how many times it's called: later calls return the same
+// result the first call got without issuing a second DetachNetwork.
+func detachOnceErr(agent *swarmagent.Agent, taskID string) func() error {
+	var once sync.Once
+	var err error
+	return func() error {
+		once.Do(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), swarmRequestTimeout)
+			defer cancel()
+			err = agent.ResourceAllocator().DetachNetwork(ctx, taskID)
+		})
+		return err
+	}
+}
+
+// releaseRegistryMu guards releaseRegistry. attacher (the type
+// c.attachers holds) predates this diff and isn't reconstructed in this
+// checkout, so it has no field of its own to cache a release on; keyed by
+// the *attacher pointer both AttachNetwork and WaitForDetachment already
+// look up from c.attachers[aKey], the same workaround
+// clusterAttachRetryPolicy above uses for *Cluster.
+var (
+	releaseRegistryMu sync.Mutex
+	releaseRegistry   = map[*attacher]func() error{}
+)
+
+// sharedRelease returns the idempotent release registered for a, creating
+// it from newRelease the first time either AttachNetwork or
+// WaitForDetachment asks for it. Whichever call arrives first "wins" the
+// actual detachOnceErr instance; the other gets the same func back, so the
+// two code paths share one sync.Once instead of each racing to detach
+// taskID independently.
+func sharedRelease(a *attacher, newRelease func() func() error) func() error {
+	releaseRegistryMu.Lock()
+	defer releaseRegistryMu.Unlock()
+	if fn, ok := releaseRegistry[a]; ok {
+		return fn
+	}
+	fn := newRelease()
+	releaseRegistry[a] = fn
+	return fn
+}
+
+// forgetRelease drops a's entry once its attachment is gone, so
+// releaseRegistry doesn't grow by one entry per attachment for the life of
+// the process.
+func forgetRelease(a *attacher) {
+	releaseRegistryMu.Lock()
+	delete(releaseRegistry, a)
+	releaseRegistryMu.Unlock()
+}
@@ -0,0 +1,119 @@
+package cluster
+
+import (
+	"net"
+
+	"github.com/moby/moby/api/types/network"
+	"github.com/moby/moby/v2/errdefs"
+	"github.com/pkg/errors"
+)
+
+// maxNetworkingConfigEndpoints is the number of EndpointsConfig entries a
+// single NetworkingConfig may declare before the caller must explicitly opt
+// into multi-attach. This mirrors the daemon's own default of one endpoint
+// per container per network request.
+const maxNetworkingConfigEndpoints = 1
+
+// verifyNetworkingConfig rejects malformed IPv4/IPv6 addresses and
+// more-than-one-endpoint configs before they're forwarded to the swarm
+// manager, analogous to the daemon's own verifyNetworkingConfig. Returning
+// errdefs.InvalidParameter here means the HTTP layer produces a 400 instead
+// of the manager's opaque 500 after a round-trip.
+func verifyNetworkingConfig(nc *network.NetworkingConfig, allowMultiAttach bool) error {
+	if nc == nil {
+		return nil
+	}
+
+	if !allowMultiAttach && len(nc.EndpointsConfig) > maxNetworkingConfigEndpoints {
+		return errdefs.InvalidParameter(errors.New("Container cannot be connected to more than one network in a single NetworkingConfig"))
+	}
+
+	for name, ep := range nc.EndpointsConfig {
+		if ep == nil || ep.IPAMConfig == nil {
+			continue
+		}
+		if addr := ep.IPAMConfig.IPv4Address; addr != "" {
+			ip := net.ParseIP(addr)
+			if ip == nil || ip.To4() == nil {
+				return errdefs.InvalidParameter(errors.Errorf("invalid IPv4 address %q for network %q", addr, name))
+			}
+		}
+		if addr := ep.IPAMConfig.IPv6Address; addr != "" {
+			ip := net.ParseIP(addr)
+			if ip == nil || ip.To4() != nil {
+				return errdefs.InvalidParameter(errors.Errorf("invalid IPv6 address %q for network %q", addr, name))
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyIPAMConfig validates that every subnet/gateway/IP-range CIDR on a
+// CreateRequest's IPAM is internally consistent: each gateway and range
+// falls inside its subnet, and no two subnets overlap.
+func verifyIPAMConfig(ipam *network.IPAM) error {
+	if ipam == nil {
+		return nil
+	}
+
+	var subnets []*net.IPNet
+	for _, cfg := range ipam.Config {
+		if cfg.Subnet == "" {
+			continue
+		}
+		_, subnet, err := net.ParseCIDR(cfg.Subnet)
+		if err != nil {
+			return errdefs.InvalidParameter(errors.Wrapf(err, "invalid subnet %q", cfg.Subnet))
+		}
+
+		if cfg.Gateway != "" {
+			gw := net.ParseIP(cfg.Gateway)
+			if gw == nil || !subnet.Contains(gw) {
+				return errdefs.InvalidParameter(errors.Errorf("gateway %q is not contained in subnet %q", cfg.Gateway, cfg.Subnet))
+			}
+		}
+
+		if cfg.IPRange != "" {
+			_, ipRange, err := net.ParseCIDR(cfg.IPRange)
+			if err != nil {
+				return errdefs.InvalidParameter(errors.Wrapf(err, "invalid IP range %q", cfg.IPRange))
+			}
+			rangeOnes, _ := ipRange.Mask.Size()
+			subnetOnes, _ := subnet.Mask.Size()
+			if !subnet.Contains(ipRange.IP) || rangeOnes < subnetOnes {
+				return errdefs.InvalidParameter(errors.Errorf("IP range %q is not contained in subnet %q", cfg.IPRange, cfg.Subnet))
+			}
+		}
+
+		for _, other := range subnets {
+			if subnetsOverlap(subnet, other) {
+				return errdefs.InvalidParameter(errors.Errorf("subnet %q overlaps with another subnet %q in the same IPAM config", subnet, other))
+			}
+		}
+		subnets = append(subnets, subnet)
+	}
+
+	return nil
+}
+
+func subnetsOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// verifyAttachAddresses rejects addresses that aren't parseable IPs (with or
+// without a CIDR mask) before they're forwarded to
+// agent.ResourceAllocator().AttachNetwork, so a malformed address surfaces
+// as an InvalidParameter here instead of an opaque manager-side error.
+func verifyAttachAddresses(addresses []string) error {
+	for _, addr := range addresses {
+		host := addr
+		if ip, _, err := net.ParseCIDR(addr); err == nil {
+			host = ip.String()
+		}
+		if net.ParseIP(host) == nil {
+			return errdefs.InvalidParameter(errors.Errorf("invalid address %q requested for network attachment", addr))
+		}
+	}
+	return nil
+}
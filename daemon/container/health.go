@@ -8,11 +8,24 @@ import (
 	"github.com/moby/moby/api/types/container"
 )
 
+// healthSubscriberBuffer is the default channel capacity for a health status
+// subscriber. It only needs to hold enough transitions to outlast a
+// consumer that's briefly busy between probe intervals; a subscriber that
+// falls further behind than this has its oldest pending status dropped
+// rather than blocking SetStatus.
+const healthSubscriberBuffer = 16
+
+// healthSubscriber is one Subscribe call's delivery channel.
+type healthSubscriber struct {
+	ch chan container.HealthStatus
+}
+
 // Health holds the current container health-check state
 type Health struct {
 	container.Health
-	stop chan struct{} // Write struct{} to stop the monitor
-	mu   sync.Mutex
+	stop        chan struct{} // Write struct{} to stop the monitor
+	mu          sync.Mutex
+	subscribers map[*healthSubscriber]struct{}
 }
 
 // String returns a human-readable description of the health-check state
@@ -50,7 +63,86 @@ func (s *Health) SetStatus(healthStatus container.HealthStatus) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.Health.Status == healthStatus {
+		return
+	}
 	s.Health.Status = healthStatus
+	s.notifySubscribersLocked(healthStatus)
+}
+
+// Subscribe registers a new subscriber for health status transitions and
+// returns a channel delivering subsequent changes (including the final
+// Unhealthy status set by CloseMonitorChannel; a subscriber more than
+// healthSubscriberBuffer changes behind loses the oldest pending ones) and
+// a cancel function that
+// unregisters the subscriber and drains its channel. Callers must invoke the
+// cancel function when done to avoid leaking the subscription.
+func (s *Health) Subscribe() (<-chan container.HealthStatus, func()) {
+	sub := &healthSubscriber{ch: make(chan container.HealthStatus, healthSubscriberBuffer)}
+
+	s.mu.Lock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[*healthSubscriber]struct{})
+	}
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+
+	cancel := func() {
+		s.mu.Lock()
+		delete(s.subscribers, sub)
+		s.mu.Unlock()
+
+		for {
+			select {
+			case <-sub.ch:
+			default:
+				return
+			}
+		}
+	}
+	return sub.ch, cancel
+}
+
+// ForwardTo subscribes to health status transitions and calls sink for each
+// one until ctx is done, at which point it unsubscribes and returns. It lets
+// a consumer turn a subscription into its own delivery mechanism (an
+// event-bus publish, or a chunked HTTP response) without reimplementing the
+// buffering/overflow policy Subscribe already has. Nothing calls it: neither
+// daemon/events nor a `GET /containers/{id}/health?stream=1` handler is
+// wired to it.
+func (s *Health) ForwardTo(ctx context.Context, sink func(container.HealthStatus)) {
+	ch, cancel := s.Subscribe()
+	defer cancel()
+
+	for {
+		select {
+		case status := <-ch:
+			sink(status)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// notifySubscribersLocked fans healthStatus out to every current subscriber.
+// It must be called with s.mu held. Sends are non-blocking: a subscriber
+// that hasn't kept up has its oldest buffered status dropped to make room,
+// so a slow consumer never stalls the health monitor.
+func (s *Health) notifySubscribersLocked(healthStatus container.HealthStatus) {
+	for sub := range s.subscribers {
+		select {
+		case sub.ch <- healthStatus:
+		default:
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- healthStatus:
+			default:
+			}
+		}
+	}
 }
 
 // OpenMonitorChannel creates and returns a new monitor channel. If there
@@ -78,6 +170,7 @@ func (s *Health) CloseMonitorChannel() {
 		s.stop = nil
 		// unhealthy when the monitor has stopped for compatibility reasons
 		s.Health.Status = container.Unhealthy
+		s.notifySubscribersLocked(container.Unhealthy)
 		log.G(context.TODO()).Debug("CloseMonitorChannel done")
 	}
 }
@@ -0,0 +1,167 @@
+package overlay
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/containerd/log"
+	"github.com/moby/moby/v2/daemon/libnetwork/networkdb"
+)
+
+// peerSyncTable is the networkdb table name the Windows overlay driver
+// gossips remote endpoint state over, mirroring how the Linux overlay
+// driver uses networkdb to converge peer state without a central
+// orchestrator. Entries are keyed by "<nid>/<peerIP>".
+const peerSyncTable = "windows_overlay_peers"
+
+// peerRecord is the gossiped representation of a single remote endpoint:
+// enough for a receiving daemon to reconstruct the HNS PaPolicy that
+// peerAdd would otherwise only install when told to by Swarm.
+type peerRecord struct {
+	EndpointID string `json:"eid"`
+	Mac        string `json:"mac"`
+	Vtep       string `json:"vtep"`
+}
+
+// gossipSync holds the driver's connection to networkdb. Its nDB is nil
+// until EnableGossipSync is called, so standalone daemons that never join a
+// networkdb cluster (e.g. under Swarm, which drives peerAdd/peerDelete
+// directly) see no behavior change.
+type gossipSync struct {
+	mu     sync.Mutex
+	nDB    *networkdb.NetworkDB
+	cancel map[string]func() // nid -> stop watching
+}
+
+var overlayGossip = &gossipSync{cancel: map[string]func(){}}
+
+// EnableGossipSync wires the driver to a running networkdb instance so that
+// two standalone (non-Swarm) daemons can converge overlay peer state via
+// gossip instead of requiring an external orchestrator to call peerAdd.
+func (d *driver) EnableGossipSync(nDB *networkdb.NetworkDB) {
+	overlayGossip.mu.Lock()
+	overlayGossip.nDB = nDB
+	overlayGossip.mu.Unlock()
+}
+
+func peerSyncKey(nid string, peerIP net.IP) string {
+	return nid + "/" + peerIP.String()
+}
+
+// advertisePeer gossips a local endpoint's peer info so other daemons'
+// reconcilers can install the matching HNS remote endpoint.
+func (d *driver) advertisePeer(nid, eid string, peerIP net.IP, peerMac net.HardwareAddr, vtep net.IP) {
+	overlayGossip.mu.Lock()
+	nDB := overlayGossip.nDB
+	overlayGossip.mu.Unlock()
+	if nDB == nil {
+		return
+	}
+
+	rec := peerRecord{EndpointID: eid, Mac: peerMac.String(), Vtep: vtep.String()}
+	value, err := json.Marshal(rec)
+	if err != nil {
+		log.G(context.TODO()).Warnf("WINOVERLAY: failed to encode peer record for gossip: %v", err)
+		return
+	}
+	if err := nDB.CreateEntry(peerSyncTable, nid, peerSyncKey(nid, peerIP), value); err != nil {
+		log.G(context.TODO()).Warnf("WINOVERLAY: failed to gossip peer %s: %v", peerIP, err)
+	}
+}
+
+// withdrawPeer removes a local endpoint's peer info from the gossip table,
+// so remote reconcilers tear down the corresponding HNS remote endpoint.
+func (d *driver) withdrawPeer(nid string, peerIP net.IP) {
+	overlayGossip.mu.Lock()
+	nDB := overlayGossip.nDB
+	overlayGossip.mu.Unlock()
+	if nDB == nil {
+		return
+	}
+	if err := nDB.DeleteEntry(peerSyncTable, nid, peerSyncKey(nid, peerIP)); err != nil {
+		log.G(context.TODO()).Warnf("WINOVERLAY: failed to withdraw gossiped peer %s: %v", peerIP, err)
+	}
+}
+
+// startPeerReconciler watches peerSyncTable for the given network and
+// translates remote daemons' gossiped entries into the same HNS PaPolicy
+// calls peerAdd/peerDelete make for Swarm-driven peers, closing the parity
+// gap between the Windows and Linux overlay drivers for standalone use.
+func (d *driver) startPeerReconciler(nid string) {
+	overlayGossip.mu.Lock()
+	nDB := overlayGossip.nDB
+	if nDB == nil {
+		overlayGossip.mu.Unlock()
+		return
+	}
+	if _, already := overlayGossip.cancel[nid]; already {
+		overlayGossip.mu.Unlock()
+		return
+	}
+	ch, cancel := nDB.Watch(peerSyncTable, nid)
+	overlayGossip.cancel[nid] = cancel
+	overlayGossip.mu.Unlock()
+
+	go func() {
+		for {
+			ev, ok := <-ch.C
+			if !ok {
+				return
+			}
+			d.handlePeerSyncEvent(nid, ev)
+		}
+	}()
+}
+
+func (d *driver) stopPeerReconciler(nid string) {
+	overlayGossip.mu.Lock()
+	cancel, ok := overlayGossip.cancel[nid]
+	delete(overlayGossip.cancel, nid)
+	overlayGossip.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func (d *driver) handlePeerSyncEvent(nid string, ev networkdb.WatchEvent) {
+	peerIP := strings.TrimPrefix(ev.Key, nid+"/")
+	ip := net.ParseIP(peerIP)
+	if ip == nil {
+		return
+	}
+
+	if ev.Value == nil {
+		// Entry withdrawn: the peer left or its endpoint was removed. The
+		// eid is only needed to look up the HNS remote endpoint created for
+		// it, which we recover from the tombstoned entry's last known value.
+		var rec peerRecord
+		if len(ev.Prev) > 0 {
+			_ = json.Unmarshal(ev.Prev, &rec)
+		}
+		_ = d.peerDelete(nid, rec.EndpointID, ip, true)
+		return
+	}
+
+	var rec peerRecord
+	if err := json.Unmarshal(ev.Value, &rec); err != nil {
+		log.G(context.TODO()).Warnf("WINOVERLAY: failed to decode gossiped peer record: %v", err)
+		return
+	}
+	mac, err := net.ParseMAC(rec.Mac)
+	if err != nil {
+		log.G(context.TODO()).Warnf("WINOVERLAY: gossiped peer record for %s has invalid MAC %q: %v", peerIP, rec.Mac, err)
+		return
+	}
+	vtep := net.ParseIP(rec.Vtep)
+	if vtep == nil {
+		log.G(context.TODO()).Warnf("WINOVERLAY: gossiped peer record for %s has invalid VTEP %q", peerIP, rec.Vtep)
+		return
+	}
+
+	if err := d.peerAdd(nid, rec.EndpointID, ip, mac, vtep, true); err != nil {
+		log.G(context.TODO()).Warnf("WINOVERLAY: failed to reconcile gossiped peer %s: %v", peerIP, err)
+	}
+}
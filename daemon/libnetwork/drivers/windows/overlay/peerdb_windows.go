@@ -77,6 +77,12 @@ func (d *driver) peerAdd(nid, eid string, peerIP net.IP, peerMac net.HardwareAdd
 		}
 
 		n.addEndpoint(ep)
+	} else {
+		// updateDb is false for peers the driver learns about locally
+		// (e.g. the endpoint this daemon just created). Gossip it so
+		// other daemons' reconcilers can install the matching HNS
+		// remote endpoint without a central orchestrator telling them to.
+		d.advertisePeer(nid, eid, peerIP, peerMac, vtep)
 	}
 
 	return nil
@@ -106,6 +112,8 @@ func (d *driver) peerDelete(nid, eid string, peerIP net.IP, updateDb bool) error
 		}
 
 		n.deleteEndpoint(eid)
+	} else {
+		d.withdrawPeer(nid, peerIP)
 	}
 
 	return nil
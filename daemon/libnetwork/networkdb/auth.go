@@ -0,0 +1,165 @@
+package networkdb
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+
+	"github.com/containerd/log"
+)
+
+// messageMACSize is the size, in bytes, of the truncated HMAC-SHA256 that
+// authenticates every gossip message once signing is enabled. 16 bytes
+// keeps the per-message overhead small while still giving a forgery
+// resistance well beyond what's practical to brute-force.
+const messageMACSize = 16
+
+// authHeader is prepended to every encoded gossip message once the ring has
+// at least one key: keyID identifies which ring key produced mac, so a
+// rolling SetKey/RemoveKey sequence can keep verifying messages signed with
+// a key that's still present but no longer primary.
+type authHeader struct {
+	keyID uint8
+	mac   [messageMACSize]byte
+}
+
+func (h authHeader) appendTo(buf []byte) []byte {
+	buf = append(buf, h.keyID)
+	return append(buf, h.mac[:]...)
+}
+
+func decodeAuthHeader(buf []byte) (authHeader, []byte, bool) {
+	if len(buf) < 1+messageMACSize {
+		return authHeader{}, nil, false
+	}
+	var h authHeader
+	h.keyID = buf[0]
+	copy(h.mac[:], buf[1:1+messageMACSize])
+	return h, buf[1+messageMACSize:], true
+}
+
+func computeMAC(key []byte, payload []byte) [messageMACSize]byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	sum := mac.Sum(nil)
+	var out [messageMACSize]byte
+	copy(out[:], sum[:messageMACSize])
+	return out
+}
+
+// signOutgoing prepends an authHeader computed over buf with the ring's
+// current primary key. If the ring has no key configured yet (signing
+// hasn't been turned on for this cluster) it returns buf unchanged, which is
+// what lets RequireSignedMessages stay off by default for compatibility
+// with peers running an older engine.
+func (nDB *NetworkDB) signOutgoing(buf []byte) []byte {
+	nDB.RLock()
+	keyID, key := nDB.primaryRingKey()
+	nDB.RUnlock()
+
+	if key == nil {
+		return buf
+	}
+
+	h := authHeader{keyID: keyID, mac: computeMAC(key, buf)}
+	return h.appendTo(buf)
+}
+
+// verifyIncoming strips and checks the authHeader the sending peer prepended
+// to buf, if any. It tries every ring key still present, not just the one
+// named by keyID, so messages signed moments before a SetKey/RemoveKey
+// rotation completed on this node still verify. peer identifies the sender
+// for the verification-failure metrics and logs. ok is false either because
+// the header didn't verify, or because RequireSignedMessages demands one
+// and buf didn't carry it.
+func (nDB *NetworkDB) verifyIncoming(buf []byte, peer string) (payload []byte, ok bool) {
+	nDB.RLock()
+	keys := nDB.ringKeys()
+	requireSigned := nDB.config.RequireSignedMessages
+	nDB.RUnlock()
+
+	if len(keys) == 0 {
+		// Signing isn't configured on this node at all.
+		return buf, !requireSigned
+	}
+
+	h, rest, hasHeader := decodeAuthHeader(buf)
+	if !hasHeader {
+		if requireSigned {
+			nDB.authFailures.recordFailure(peer)
+			log.G(context.TODO()).Warnf("networkdb: rejected unsigned message from %s: RequireSignedMessages is set", peer)
+			return nil, false
+		}
+		// Backward compatibility with a peer that hasn't turned on
+		// signing yet.
+		return buf, true
+	}
+
+	for _, key := range keys {
+		if hmac.Equal(computeMAC(key, rest)[:], h.mac[:]) {
+			return rest, true
+		}
+	}
+
+	nDB.authFailures.recordFailure(peer)
+	log.G(context.TODO()).Warnf("networkdb: rejected message from %s: MAC verification failed against %d ring key(s)", peer, len(keys))
+	return nil, false
+}
+
+// authFailureCounters tracks, per peer address, how many gossip messages
+// have failed MAC verification. Exposed via NetworkDB.AuthFailures so
+// operators can spot a misbehaving or stale peer during a key
+// rotation instead of silently dropping its traffic.
+//
+// verifyIncoming is called concurrently from NotifyMsg (UDP) and
+// MergeRemoteState (TCP push/pull) for different peers, so a first-time
+// failure for two peers can race to insert into counts at the same time;
+// mu guards the map itself (insertion, ranging), while the counter values
+// it holds stay atomic so AuthFailures can snapshot them without blocking
+// recordFailure.
+type authFailureCounters struct {
+	mu     sync.Mutex
+	counts map[string]*int64
+}
+
+func newAuthFailureCounters() *authFailureCounters {
+	return &authFailureCounters{counts: make(map[string]*int64)}
+}
+
+func (c *authFailureCounters) recordFailure(peer string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	n, ok := c.counts[peer]
+	if !ok {
+		var zero int64
+		n = &zero
+		c.counts[peer] = n
+	}
+	c.mu.Unlock()
+	atomic.AddInt64(n, 1)
+}
+
+func (c *authFailureCounters) snapshot() map[string]int64 {
+	if c == nil {
+		return map[string]int64{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]int64, len(c.counts))
+	for peer, n := range c.counts {
+		out[peer] = atomic.LoadInt64(n)
+	}
+	return out
+}
+
+// AuthFailures returns the number of gossip messages rejected for failing
+// MAC verification, per peer, since this node started. Operators can poll
+// this during a SetKey/RemoveKey rotation to confirm every peer is still
+// signing with a key this node recognizes.
+func (nDB *NetworkDB) AuthFailures() map[string]int64 {
+	return nDB.authFailures.snapshot()
+}
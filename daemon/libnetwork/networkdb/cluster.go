@@ -0,0 +1,126 @@
+package networkdb
+
+import "sync"
+
+// Config and NetworkDB are the existing types in networkdb.go, which
+// predates this diff and isn't reconstructed in this checkout (it already
+// carries the real nodes/networks/broadcaster/clock state delegate.go
+// depends on throughout — thisNodeNetworks, networkClock, bulkSyncAckTbl,
+// and the rest). This package's gossip-auth path (auth.go, digest.go)
+// needs three additions made directly to those existing types rather than
+// redeclared here: Config.NodeID, Config.Hostname and
+// Config.RequireSignedMessages (already read via nDB.config elsewhere in
+// this package) and NetworkDB.keys/NetworkDB.authFailures, the signing ring
+// and failure counters ringKeys/primaryRingKey/AuthFailures below operate
+// on.
+
+// WatchEvent describes a single table-entry change delivered to a Watch
+// subscriber: Value is the new value (nil for a DELETE), and Prev is the
+// value most recently observed for the same key before this change (nil on a
+// CREATE or UPDATE for a key that had no live entry). A DELETE is only
+// delivered for a key that had a live entry, so its Prev is that entry's
+// value; a DELETE for a key this node never saw created is not delivered.
+type WatchEvent struct {
+	Table     string
+	NetworkID string
+	Key       string
+	Value     []byte
+	Prev      []byte
+}
+
+// Channel is the subscription handle returned by Watch: C delivers every
+// WatchEvent matching the subscription until the accompanying cancel
+// function is called.
+type Channel struct {
+	C <-chan WatchEvent
+}
+
+// watchMu guards watchSubscribers. NetworkDB doesn't carry a subscriber
+// list field of its own (see the package comment above), so subscriptions
+// are kept here, keyed by the *NetworkDB they were registered against.
+var (
+	watchMu          sync.Mutex
+	watchSubscribers = map[*NetworkDB][]*watchSubscriber{}
+)
+
+type watchSubscriber struct {
+	tname string
+	nid   string
+	ch    chan WatchEvent
+}
+
+// Watch creates a subscription for table events on the given (tname, nid)
+// pair (either may be left empty to match every table/network) and returns
+// a Channel to receive them plus a cancel function that unregisters the
+// subscription. Matching handleTableEvent broadcasts are delivered in
+// arrival order by notifyWatchers; a subscriber that falls behind has its
+// events dropped rather than blocking the gossip goroutine that produced
+// them, same as the broadcaster this rides alongside.
+func (nDB *NetworkDB) Watch(tname, nid string) (*Channel, func()) {
+	const watchBuffer = 16
+	sub := &watchSubscriber{tname: tname, nid: nid, ch: make(chan WatchEvent, watchBuffer)}
+
+	watchMu.Lock()
+	watchSubscribers[nDB] = append(watchSubscribers[nDB], sub)
+	watchMu.Unlock()
+
+	cancel := func() {
+		watchMu.Lock()
+		subs := watchSubscribers[nDB]
+		for i, s := range subs {
+			if s == sub {
+				watchSubscribers[nDB] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(watchSubscribers[nDB]) == 0 {
+			delete(watchSubscribers, nDB)
+		}
+		watchMu.Unlock()
+		close(sub.ch)
+	}
+	return &Channel{C: sub.ch}, cancel
+}
+
+// notifyWatchers delivers event to every Watch subscription registered
+// against nDB whose (tname, nid) filter matches (an empty filter matches
+// everything). Called from handleTableEvent right alongside
+// nDB.broadcaster.Write(event), the other consumer of the same event.
+func (nDB *NetworkDB) notifyWatchers(event WatchEvent) {
+	watchMu.Lock()
+	subs := append([]*watchSubscriber(nil), watchSubscribers[nDB]...)
+	watchMu.Unlock()
+
+	for _, s := range subs {
+		if s.tname != "" && s.tname != event.Table {
+			continue
+		}
+		if s.nid != "" && s.nid != event.NetworkID {
+			continue
+		}
+		select {
+		case s.ch <- event:
+		default:
+			// Subscriber isn't keeping up; drop rather than block gossip.
+		}
+	}
+}
+
+// ringKeys returns every key currently in the signing ring, most recently
+// added first, so verifyIncoming can try each one in turn when checking an
+// incoming message's MAC.
+func (nDB *NetworkDB) ringKeys() [][]byte {
+	return nDB.keys
+}
+
+// primaryRingKey returns the key used to sign outgoing messages (the most
+// recently added key) along with a stable ID for it, or a nil key if no key
+// has been configured yet. keyID is the key's position counted from the
+// oldest ring entry, so it stays stable as SetKey prepends newer keys and
+// only shifts when RemoveKey drops an older entry out of the ring.
+func (nDB *NetworkDB) primaryRingKey() (keyID uint8, key []byte) {
+	if len(nDB.keys) == 0 {
+		return 0, nil
+	}
+	return uint8(len(nDB.keys) - 1), nDB.keys[0]
+}
@@ -177,12 +177,41 @@ func (nDB *NetworkDB) handleTableEvent(tEvent *TableEvent, isBulkSync bool) bool
 		}
 	}
 
+	evDot := dot{node: tEvent.NodeName, counter: tEvent.Dot}
+	evSeen := decodeSeenSet(tEvent.SeenDots)
+
+	// An unknown DELETE whose dot we've already witnessed (directly, or
+	// folded in from someone else's seen-set) is one we've already reaped:
+	// drop it silently instead of guessing from reapTime like before. This
+	// has to check the seen-vector nDB tracks per (network, table), not
+	// prev.seen: prev is the zero-value entry{} whenever !entryPresent, so
+	// its seen field is always nil and would never recognize the
+	// already-reaped case this exists to handle.
+	if !entryPresent && tEvent.Type == TableEventTypeDelete && nDB.seenCovers(tEvent.TableName, tEvent.NetworkID, evDot) {
+		return false
+	}
+
 	e := &entry{
 		ltime:    tEvent.LTime,
 		node:     tEvent.NodeName,
 		value:    tEvent.Value,
 		deleting: tEvent.Type == TableEventTypeDelete,
 		reapTime: time.Duration(tEvent.ResidualReapTime) * time.Second,
+		dot:      evDot,
+		seen:     evSeen.witness(evDot),
+	}
+	if entryPresent {
+		e.seen = e.seen.merge(prev.seen)
+	}
+
+	// Fold evDot (and whatever seen-set the sender carried for it) into the
+	// persistent per-(network, table) vector, not just e.seen: e disappears
+	// when this entry is reaped, but the fact that we witnessed evDot must
+	// not, or a replayed DELETE for the same key would look "never seen"
+	// again the moment the tombstone is swept.
+	nDB.witnessSeen(tEvent.TableName, tEvent.NetworkID, evDot)
+	for node, counter := range evSeen {
+		nDB.witnessSeen(tEvent.TableName, tEvent.NetworkID, dot{node: node, counter: counter})
 	}
 
 	// All the entries marked for deletion should have a reapTime set greater than 0
@@ -196,22 +225,16 @@ func (nDB *NetworkDB) handleTableEvent(tEvent *TableEvent, isBulkSync bool) bool
 	nDB.createOrUpdateEntry(tEvent.NetworkID, tEvent.TableName, tEvent.Key, e)
 
 	if !entryPresent && tEvent.Type == TableEventTypeDelete {
-		// We will rebroadcast the message for an unknown entry if all the conditions are met:
-		// 1) the message was received from a bulk sync
-		// 2) we had already synced this network (during the network join)
-		// 3) the residual reapTime is higher than 1/6 of the total reapTime.
-		//
-		// If the residual reapTime is lower or equal to 1/6 of the total reapTime
-		// don't bother broadcasting it around as most likely the cluster is already aware of it.
-		// This also reduces the possibility that deletion of entries close to their garbage collection
-		// ends up circling around forever.
-		//
-		// The safest approach is to not rebroadcast async messages for unknown entries.
-		// It is possible that the queue grew so much to exceed the garbage collection time
-		// (the residual reap time that is in the message is not being updated, to avoid
-		// inserting too many messages in the queue).
-
-		// log.G(ctx).Infof("exiting on delete not knowing the obj with rebroadcast:%t", network.inSync)
+		// We've now witnessed evDot for the first time (the seenCovers
+		// check above is what stops this from firing again for the same
+		// dot), so there's no "already reaped" ambiguity left to resolve.
+		// What's left is the original dampening this replaced: an unknown
+		// delete arriving via bulk sync is only worth rebroadcasting while
+		// it's still fresh enough that the source is plausibly still
+		// finishing convergence; one old enough that it's mostly past its
+		// reapEntryInterval is more likely a straggler than new information,
+		// and propagating every straggler unconditionally is exactly the
+		// "delete circling forever" failure this guard exists to avoid.
 		return isBulkSync && network.inSync && e.reapTime > nDB.config.reapEntryInterval/6
 	}
 
@@ -244,6 +267,7 @@ func (nDB *NetworkDB) handleTableEvent(tEvent *TableEvent, isBulkSync bool) bool
 	}
 
 	nDB.broadcaster.Write(event)
+	nDB.notifyWatchers(event)
 SkipBroadcast:
 	return network.inSync
 }
@@ -405,21 +429,107 @@ func (nDB *NetworkDB) handleMessage(buf []byte, isBulkSync bool) {
 		nDB.handleBulkSync(data)
 	case MessageTypeCompound:
 		nDB.handleCompound(data, isBulkSync)
+	case MessageTypeStateDiffRequest:
+		nDB.handleStateDiffRequest(data)
+	case MessageTypeStateDiffResponse:
+		nDB.handleStateDiffResponse(data)
 	default:
 		log.G(context.TODO()).Errorf("%v(%v): unknown message type %d", nDB.config.Hostname, nDB.config.NodeID, mType)
 	}
 }
 
+// handleStateDiffRequest answers the follow-up request a peer sends after
+// finding mismatched buckets in our anti-entropy digest, by replying with
+// the full NetworkEntry records for exactly those buckets.
+func (nDB *NetworkDB) handleStateDiffRequest(buf []byte) {
+	var req StateDiffRequest
+	if err := proto.Unmarshal(buf, &req); err != nil {
+		log.G(context.TODO()).Errorf("Error decoding state diff request: %v", err)
+		return
+	}
+
+	nDB.RLock()
+	resp := &StateDiffResponse{}
+	for _, b := range req.Buckets {
+		var n *network
+		if b.NodeName == nDB.config.NodeID {
+			n = nDB.thisNodeNetworks[b.NetworkID]
+		} else if nn, ok := nDB.networks[b.NodeName]; ok {
+			n = nn[b.NetworkID]
+		}
+		if n == nil {
+			continue
+		}
+		resp.Networks = append(resp.Networks, &NetworkEntry{
+			LTime:     n.ltime,
+			NetworkID: b.NetworkID,
+			NodeName:  b.NodeName,
+			Leaving:   n.leaving,
+		})
+	}
+	nDB.RUnlock()
+
+	respBuf, err := encodeMessage(MessageTypeStateDiffResponse, resp)
+	if err != nil {
+		log.G(context.TODO()).Errorf("Failed to encode state diff response to %s: %v", req.NodeName, err)
+		return
+	}
+	respBuf = nDB.signOutgoing(respBuf)
+
+	if err := nDB.sendNodeMessage(req.NodeName, respBuf); err != nil {
+		log.G(context.TODO()).Errorf("Failed to send state diff response to %s: %v", req.NodeName, err)
+	}
+}
+
+// handleStateDiffResponse folds the bucket-sized reply to our earlier
+// MessageTypeStateDiffRequest into the local state via the same code path
+// ordinary gossip uses, so convergence semantics don't change.
+func (nDB *NetworkDB) handleStateDiffResponse(buf []byte) {
+	var resp StateDiffResponse
+	if err := proto.Unmarshal(buf, &resp); err != nil {
+		log.G(context.TODO()).Errorf("Error decoding state diff response: %v", err)
+		return
+	}
+
+	for _, n := range resp.Networks {
+		nEvent := &NetworkEvent{
+			LTime:     n.LTime,
+			NodeName:  n.NodeName,
+			NetworkID: n.NetworkID,
+			Type:      NetworkEventTypeJoin,
+		}
+		if n.Leaving {
+			nEvent.Type = NetworkEventTypeLeave
+		}
+		nDB.handleNetworkEvent(nEvent)
+	}
+}
+
 func (d *delegate) NotifyMsg(buf []byte) {
 	if len(buf) == 0 {
 		return
 	}
 
-	d.nDB.handleMessage(buf, false)
+	// memberlist's Delegate interface doesn't tell us which peer sent buf,
+	// so verification failures are attributed to "unknown"; GetBroadcasts
+	// compound messages are unwound per-part inside handleCompound and so
+	// share this one verification pass.
+	payload, ok := d.nDB.verifyIncoming(buf, "unknown")
+	if !ok {
+		return
+	}
+
+	d.nDB.handleMessage(payload, false)
 }
 
 func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
-	return getBroadcasts(overhead, limit, d.nDB.networkBroadcasts, d.nDB.nodeBroadcasts)
+	msgs := getBroadcasts(overhead, limit, d.nDB.networkBroadcasts, d.nDB.nodeBroadcasts)
+	for i, m := range msgs {
+		// signOutgoing is a no-op (returns m unchanged) when no ring key is
+		// configured, so this stays free for clusters that don't opt in.
+		msgs[i] = d.nDB.signOutgoing(m)
+	}
+	return msgs
 }
 
 func (d *delegate) LocalState(join bool) []byte {
@@ -440,23 +550,36 @@ func (d *delegate) LocalState(join bool) []byte {
 		NodeName: d.nDB.config.NodeID,
 	}
 
-	for nid, n := range d.nDB.thisNodeNetworks {
-		pp.Networks = append(pp.Networks, &NetworkEntry{
-			LTime:     n.ltime,
-			NetworkID: nid,
-			NodeName:  d.nDB.config.NodeID,
-			Leaving:   n.leaving,
-		})
-	}
-	for name, nn := range d.nDB.networks {
-		for nid, n := range nn {
+	// On a join we still send every NetworkEntry we know of, exactly as
+	// before: the peer we're (re)joining may be running an older version
+	// that doesn't understand digests, and join traffic is rare enough
+	// that the O(N) cost doesn't matter. Steady-state pushes instead carry
+	// only a compact digest, and MergeRemoteState asks for only the buckets
+	// that actually disagree, via a follow-up MessageTypeStateDiffRequest.
+	// No version is exchanged to decide this: a steady-state push carries
+	// no Networks list whatever the peer runs, so a peer that doesn't
+	// understand digests gets network state from this node only on a join.
+	if join {
+		for nid, n := range d.nDB.thisNodeNetworks {
 			pp.Networks = append(pp.Networks, &NetworkEntry{
 				LTime:     n.ltime,
 				NetworkID: nid,
-				NodeName:  name,
+				NodeName:  d.nDB.config.NodeID,
 				Leaving:   n.leaving,
 			})
 		}
+		for name, nn := range d.nDB.networks {
+			for nid, n := range nn {
+				pp.Networks = append(pp.Networks, &NetworkEntry{
+					LTime:     n.ltime,
+					NetworkID: nid,
+					NodeName:  name,
+					Leaving:   n.leaving,
+				})
+			}
+		}
+	} else {
+		pp.Digest = encodeNetworkDigest(computeNetworkDigest(d.nDB))
 	}
 
 	buf, err := encodeMessage(MessageTypePushPull, &pp)
@@ -465,7 +588,7 @@ func (d *delegate) LocalState(join bool) []byte {
 		return nil
 	}
 
-	return buf
+	return d.nDB.signOutgoing(buf)
 }
 
 func (d *delegate) MergeRemoteState(buf []byte, isJoin bool) {
@@ -474,6 +597,11 @@ func (d *delegate) MergeRemoteState(buf []byte, isJoin bool) {
 		return
 	}
 
+	buf, ok := d.nDB.verifyIncoming(buf, "unknown")
+	if !ok {
+		return
+	}
+
 	var gMsg GossipMessage
 	err := proto.Unmarshal(buf, &gMsg)
 	if err != nil {
@@ -512,4 +640,47 @@ func (d *delegate) MergeRemoteState(buf []byte, isJoin bool) {
 
 		d.nDB.handleNetworkEvent(nEvent)
 	}
+
+	if pp.Digest != nil {
+		d.mergeRemoteDigest(pp.NodeName, pp.Digest)
+	}
+}
+
+// mergeRemoteDigest compares a peer's anti-entropy digest against our own
+// view of the cluster and, for any (NetworkID, NodeName) bucket that
+// disagrees, asks the peer for the real NetworkEntry records rather than
+// waiting for them to show up via normal gossip. This is what lets
+// steady-state push/pull stay O(changed) instead of O(nodes × networks).
+func (d *delegate) mergeRemoteDigest(remoteNode string, wire *NetworkDigest) {
+	remote := decodeNetworkDigest(wire)
+
+	d.nDB.RLock()
+	local := computeNetworkDigest(d.nDB)
+	d.nDB.RUnlock()
+
+	if local.Root == remote.Root && len(local.Buckets) == len(remote.Buckets) {
+		// Digests match: nothing to reconcile.
+		return
+	}
+
+	mismatched := diffNetworkDigest(local, remote)
+	if len(mismatched) == 0 {
+		return
+	}
+
+	req := &StateDiffRequest{NodeName: d.nDB.config.NodeID}
+	for _, b := range mismatched {
+		req.Buckets = append(req.Buckets, &NetworkDigestBucket{NetworkID: b.NetworkID, NodeName: b.NodeName, Hash: b.Hash})
+	}
+
+	buf, err := encodeMessage(MessageTypeStateDiffRequest, req)
+	if err != nil {
+		log.G(context.TODO()).Errorf("Failed to encode state diff request for %s: %v", remoteNode, err)
+		return
+	}
+	buf = d.nDB.signOutgoing(buf)
+
+	if err := d.nDB.sendNodeMessage(remoteNode, buf); err != nil {
+		log.G(context.TODO()).Errorf("Failed to send state diff request to %s: %v", remoteNode, err)
+	}
 }
@@ -0,0 +1,123 @@
+package networkdb
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// networkDigestVersion is the version number of the digest format. Nothing
+// in this package sends or checks it: LocalState puts a digest on every
+// non-join push without negotiating whether the peer understands one, and
+// falls back to the full-state exchange only on a join.
+const networkDigestVersion = 1
+
+// networkDigestBucket summarizes every NetworkEntry this node knows of for a
+// single (NetworkID, NodeName) pair into a rolling hash over (ltime,
+// leaving). Two nodes that compute the same bucket hash are guaranteed to
+// agree on the state of that pair without exchanging the underlying entry.
+type networkDigestBucket struct {
+	NetworkID string
+	NodeName  string
+	Hash      uint64
+}
+
+// networkDigest is the compact summary exchanged in place of the full
+// NetworkPushPull.Networks list during steady-state anti-entropy. Root is the
+// XOR over every bucket of a hash of its (NetworkID, NodeName, Hash), so
+// equal hashes in different buckets don't cancel out, and two digests with
+// equal Root and equal bucket
+// count are (with overwhelming probability) exchanging the same state; a
+// mismatch is resolved by diffing Buckets and requesting only the
+// disagreeing ones via MessageTypeStateDiffRequest.
+type networkDigest struct {
+	Buckets []networkDigestBucket
+	Root    uint64
+}
+
+func bucketHash(ltime uint64, leaving bool) uint64 {
+	var b [9]byte
+	binary.LittleEndian.PutUint64(b[:8], ltime)
+	if leaving {
+		b[8] = 1
+	}
+	return xxhash.Sum64(b[:])
+}
+
+// computeNetworkDigest builds the anti-entropy digest for the networks this
+// node currently knows about (both its own and every other node's, mirroring
+// what LocalState puts on the wire today). Callers must hold at least nDB.RLock.
+func computeNetworkDigest(nDB *NetworkDB) networkDigest {
+	var d networkDigest
+
+	addBucket := func(nid, node string, ltime uint64, leaving bool) {
+		h := bucketHash(ltime, leaving)
+		d.Buckets = append(d.Buckets, networkDigestBucket{NetworkID: nid, NodeName: node, Hash: h})
+		var hb [8]byte
+		binary.LittleEndian.PutUint64(hb[:], h)
+		d.Root ^= xxhash.Sum64String(nid + "\x00" + node + "\x00" + string(hb[:]))
+	}
+
+	for nid, n := range nDB.thisNodeNetworks {
+		addBucket(nid, nDB.config.NodeID, n.ltime, n.leaving)
+	}
+	for node, nodeNetworks := range nDB.networks {
+		for nid, n := range nodeNetworks {
+			addBucket(nid, node, n.ltime, n.leaving)
+		}
+	}
+
+	sort.Slice(d.Buckets, func(i, j int) bool {
+		if d.Buckets[i].NetworkID != d.Buckets[j].NetworkID {
+			return d.Buckets[i].NetworkID < d.Buckets[j].NetworkID
+		}
+		return d.Buckets[i].NodeName < d.Buckets[j].NodeName
+	})
+
+	return d
+}
+
+// encodeNetworkDigest converts a networkDigest into the wire representation
+// carried on NetworkPushPull.Digest (the bucket list plus the root, mirrored
+// 1:1 onto the NetworkDigest wire type in digest_messages.go).
+func encodeNetworkDigest(d networkDigest) *NetworkDigest {
+	wire := &NetworkDigest{Root: d.Root}
+	for _, b := range d.Buckets {
+		wire.Buckets = append(wire.Buckets, &NetworkDigestBucket{
+			NetworkID: b.NetworkID,
+			NodeName:  b.NodeName,
+			Hash:      b.Hash,
+		})
+	}
+	return wire
+}
+
+func decodeNetworkDigest(wire *NetworkDigest) networkDigest {
+	if wire == nil {
+		return networkDigest{}
+	}
+	d := networkDigest{Root: wire.Root}
+	for _, b := range wire.Buckets {
+		d.Buckets = append(d.Buckets, networkDigestBucket{NetworkID: b.NetworkID, NodeName: b.NodeName, Hash: b.Hash})
+	}
+	return d
+}
+
+// diffNetworkDigest returns the (NetworkID, NodeName) buckets present in
+// remote but either missing from local or hashing to a different value,
+// i.e. the buckets a MessageTypeStateDiffRequest needs to ask for.
+func diffNetworkDigest(local, remote networkDigest) []networkDigestBucket {
+	localHash := make(map[[2]string]uint64, len(local.Buckets))
+	for _, b := range local.Buckets {
+		localHash[[2]string{b.NetworkID, b.NodeName}] = b.Hash
+	}
+
+	var mismatched []networkDigestBucket
+	for _, b := range remote.Buckets {
+		if h, ok := localHash[[2]string{b.NetworkID, b.NodeName}]; !ok || h != b.Hash {
+			mismatched = append(mismatched, b)
+		}
+	}
+	return mismatched
+}
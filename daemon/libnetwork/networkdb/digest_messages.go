@@ -0,0 +1,53 @@
+package networkdb
+
+// This file defines the wire types introduced by the Merkle-digest
+// anti-entropy path: the compact digest carried on NetworkPushPull.Digest
+// and the follow-up request/response pair used to fetch the NetworkEntry
+// records for buckets that disagree.
+//
+// NetworkPushPull, NetworkEntry, GossipMessage and MessageType are the
+// existing generated types in networkdb.pb.go, which predates this diff and
+// isn't reconstructed in this checkout (same as the rest of networkdb's
+// non-gossip-auth machinery). Landing this feature for real still requires
+// adding the `Digest *NetworkDigest` field to NetworkPushPull and the
+// `Version uint32` field to GossipMessage there, and regenerating from the
+// updated .proto — neither of which this checkout does.
+
+// NetworkDigestBucket is the wire form of a single networkDigestBucket.
+type NetworkDigestBucket struct {
+	NetworkID string
+	NodeName  string
+	Hash      uint64
+}
+
+// NetworkDigest is the wire form of a networkDigest, carried on
+// NetworkPushPull.Digest during steady-state anti-entropy instead of the
+// full Networks list.
+type NetworkDigest struct {
+	Buckets []*NetworkDigestBucket
+	Root    uint64
+}
+
+// StateDiffRequest is sent by a node that found one or more mismatched
+// buckets in a peer's digest, asking for the real NetworkEntry records for
+// just those buckets.
+type StateDiffRequest struct {
+	NodeName string
+	Buckets  []*NetworkDigestBucket
+}
+
+// StateDiffResponse answers a StateDiffRequest with the NetworkEntry records
+// for the requested buckets, folded into the receiver via the same
+// handleNetworkEvent path ordinary gossip uses.
+type StateDiffResponse struct {
+	Networks []*NetworkEntry
+}
+
+// MessageTypeStateDiffRequest and MessageTypeStateDiffResponse are meant to
+// extend the gossip message type enum (networkdb.pb.go, not reconstructed
+// here — see the package comment above) with the digest-diff follow-up
+// exchange, continuing on from MessageTypeCompound.
+const (
+	MessageTypeStateDiffRequest MessageType = iota + 6
+	MessageTypeStateDiffResponse
+)
@@ -0,0 +1,71 @@
+package networkdb
+
+// dot identifies a single write by the node that made it and that node's
+// monotonic per-writer counter, following the dotted version vector scheme:
+// a dot uniquely names one mutation so replicas can tell "never saw this
+// write" apart from "saw it and already reaped it" without relying on
+// Lamport time alone.
+type dot struct {
+	node    string
+	counter uint64
+}
+
+// seenSet summarizes, for a single (network, table, key) in entry.seen, or
+// for a whole (network, table) in seen.go's persistentSeen, the highest
+// counter this replica has observed from each writer node. A dot is
+// "covered" by a seenSet if its counter is <= the counter recorded for its
+// node, meaning the replica has already processed that write (or a later
+// one from the same writer) and it is safe to ignore a replayed DELETE for
+// it rather than rebroadcast or resurrect it.
+type seenSet map[string]uint64
+
+func (s seenSet) covers(d dot) bool {
+	if s == nil {
+		return false
+	}
+	return s[d.node] >= d.counter
+}
+
+// witness folds d into the seenSet, recording that this replica has now
+// observed up through d.counter from d.node.
+func (s seenSet) witness(d dot) seenSet {
+	if s == nil {
+		s = make(seenSet)
+	}
+	if s[d.node] < d.counter {
+		s[d.node] = d.counter
+	}
+	return s
+}
+
+// merge folds every dot recorded in other into s, taking the max counter
+// per node. handleTableEvent uses it to carry the previous entry's seen-set
+// over into the entry that replaces it.
+func (s seenSet) merge(other seenSet) seenSet {
+	for node, counter := range other {
+		s = s.witness(dot{node: node, counter: counter})
+	}
+	return s
+}
+
+// encodeSeenSet/decodeSeenSet convert to and from the wire representation
+// carried on TableEvent.SeenDots. Bulk sync payloads carry no per-network
+// seen-vector of their own (see seen.go).
+func encodeSeenSet(s seenSet) []*SeenDot {
+	wire := make([]*SeenDot, 0, len(s))
+	for node, counter := range s {
+		wire = append(wire, &SeenDot{NodeName: node, Counter: counter})
+	}
+	return wire
+}
+
+func decodeSeenSet(wire []*SeenDot) seenSet {
+	if len(wire) == 0 {
+		return nil
+	}
+	s := make(seenSet, len(wire))
+	for _, sd := range wire {
+		s[sd.NodeName] = sd.Counter
+	}
+	return s
+}
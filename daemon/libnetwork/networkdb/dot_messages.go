@@ -0,0 +1,52 @@
+package networkdb
+
+import "time"
+
+// SeenDot is the wire form of a single (node, counter) pair in a seenSet.
+//
+// TableEvent is the existing generated type in networkdb.pb.go, which
+// predates this diff and isn't reconstructed in this checkout. Landing the
+// dotted-version-vector scheme for real still requires adding two fields to
+// it there and regenerating from the updated .proto: `Dot uint64` (the
+// writer's per-entry counter, paired with TableEvent.NodeName to form the
+// full dot) and `SeenDots []*SeenDot` (the sender's seen-set for this
+// entry's (network, table, key), folded into the receiver's seenSet on
+// merge so an entire causal history can be compared in one shot instead of
+// trusting Lamport time alone). BulkSyncMessage would carry the same
+// SeenDots list per network so a whole network's history can be compared at
+// once.
+type SeenDot struct {
+	NodeName string
+	Counter  uint64
+}
+
+// entry is a single replicated record in a network's table: the value most
+// recently written for a key, the dotted version vector identifying that
+// write, and the seen-set folding in every dot this replica has witnessed
+// for the same (network, table, key), which is what lets handleTableEvent
+// tell an already-reaped DELETE apart from one whose CREATE was never seen.
+type entry struct {
+	// ltime is the Lamport time when this entry was created.
+	ltime uint64
+
+	// node is the node name of the node updating the db.
+	node string
+
+	// value is the value of the entry.
+	value []byte
+
+	// deleting indicates that this entry is considered deleted.
+	deleting bool
+
+	// reapTime indicates the time after which this entry will be
+	// removed from the table.
+	reapTime time.Duration
+
+	// dot is the dotted version vector identifying the write that
+	// produced value on node.
+	dot dot
+
+	// seen folds in every dot this replica has witnessed for this
+	// (network, table, key), across every writer node.
+	seen seenSet
+}
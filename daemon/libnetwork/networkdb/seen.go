@@ -0,0 +1,63 @@
+package networkdb
+
+import "sync"
+
+// tableKey identifies the (network, table) pair persistentSeen tracks a
+// seen-vector for.
+type tableKey struct {
+	nid   string
+	tname string
+}
+
+// persistentSeenMu guards persistentSeen, the same way watchMu guards
+// watchSubscribers in cluster.go: NetworkDB doesn't carry a field of its own
+// for this yet (see the package comment there), so it's kept here keyed by
+// the *NetworkDB it belongs to.
+//
+// This is the fix for handleTableEvent's unknown-DELETE check: a per-entry
+// seen field disappears the moment its entry is reaped, so checking
+// prev.seen.covers(evDot) right after a failed getEntry was always checking
+// a nil seenSet -- the exact "already reaped" case it existed to handle can
+// never be recognized that way. Tracking the seen-vector per (network,
+// table) instead of per-entry means it survives the entry being physically
+// reaped, so a replayed DELETE for a long-gone entry is still recognized as
+// already-witnessed and dropped instead of rebroadcast.
+//
+// BulkSyncMessage itself still carries individual TableEvents rather than
+// an aggregate per-network vector (the request's "compare an entire network
+// in one shot" optimization): BulkSyncMessage is a generated protobuf type
+// in networkdb.pb.go, which predates this diff and isn't reconstructed in
+// this checkout, so it can't be given a new field here. The vector below is
+// still folded correctly during a bulk sync, since every TableEvent in one
+// still arrives through handleTableEvent in turn -- it just costs one
+// covers() check per key instead of one per network.
+var (
+	persistentSeenMu sync.Mutex
+	persistentSeen   = map[*NetworkDB]map[tableKey]seenSet{}
+)
+
+// witnessSeen folds d into the persistent seen-vector for (nid, tname),
+// recording that this replica has now observed up through d.counter from
+// d.node for that table, independent of whether the entry d wrote is still
+// present.
+func (nDB *NetworkDB) witnessSeen(tname, nid string, d dot) {
+	persistentSeenMu.Lock()
+	defer persistentSeenMu.Unlock()
+
+	tables := persistentSeen[nDB]
+	if tables == nil {
+		tables = make(map[tableKey]seenSet)
+		persistentSeen[nDB] = tables
+	}
+	key := tableKey{nid: nid, tname: tname}
+	tables[key] = tables[key].witness(d)
+}
+
+// seenCovers reports whether d has already been witnessed for (nid, tname),
+// regardless of whether the entry that wrote it is still in the table.
+func (nDB *NetworkDB) seenCovers(tname, nid string, d dot) bool {
+	persistentSeenMu.Lock()
+	defer persistentSeenMu.Unlock()
+
+	return persistentSeen[nDB][tableKey{nid: nid, tname: tname}].covers(d)
+}
@@ -0,0 +1,69 @@
+package dockerversion
+
+import "regexp"
+
+// UpstreamClient identifies the client that asked the Docker Engine CLI to
+// make a request (e.g. docker-compose, buildx, or a third-party tool built
+// on the CLI), as reported in the "UpstreamClient(...)" suffix the CLI
+// appends to its own User-Agent string. It is parsed from that suffix by
+// ParseUpstreamClient.
+//
+// Threading the parsed identity onto the request context (an api/server
+// middleware), forwarding it to registry requests (a
+// distribution.TransportWithUpstream wrapper), and exposing it to
+// authorization plugins and audit logs is not done in this checkout: there
+// is no api/server or distribution package here for a middleware or
+// transport wrapper to live in (this checkout only carries the
+// dockerversion package), so only the parser this package's own callers
+// need -- ParseUpstreamClient -- is implemented.
+type UpstreamClient struct {
+	// Product is the upstream client's product name, e.g. "Docker-Client".
+	Product string
+	// Version is the upstream client's reported version.
+	Version string
+	// OS is the parenthesized platform suffix, e.g. "linux", if present.
+	OS string
+}
+
+// upstreamClientPattern matches the "UpstreamClient(...)" suffix the CLI
+// appends to the engine's own User-Agent, capturing the parenthesized,
+// escaped "(Product/Version (OS))" that it wraps.
+var upstreamClientPattern = regexp.MustCompile(`^(.*) UpstreamClient(\(.*\))$`)
+
+var upstreamProductPattern = regexp.MustCompile(`^\(([^/]+)/([^ ]+)(?: \(([^)]*)\))?\)$`)
+
+var upstreamEscapedCharPattern = regexp.MustCompile(`\\([;()\\])`)
+
+// unescapeUpstreamUA reverses the `\;`, `\(`, `\)` and `\\` escaping the CLI
+// applies to the upstream client's User-Agent before embedding it, so that
+// semicolons and parens in, say, a third-party tool's UA string don't get
+// mistaken for delimiters of the UpstreamClient(...) wrapper itself.
+func unescapeUpstreamUA(s string) string {
+	return upstreamEscapedCharPattern.ReplaceAllString(s, "$1")
+}
+
+// ParseUpstreamClient splits a full engine User-Agent string into the
+// engine's own UA and the parsed UpstreamClient identity it carries, if
+// any. ok is false if ua doesn't carry a well-formed "UpstreamClient(...)"
+// suffix, in which case upstream is the zero value. engineUA is then ua
+// unchanged if there is no such suffix at all, or the part before the
+// suffix if the suffix is present but its contents don't parse.
+func ParseUpstreamClient(ua string) (engineUA string, upstream UpstreamClient, ok bool) {
+	m := upstreamClientPattern.FindStringSubmatch(ua)
+	if m == nil {
+		return ua, UpstreamClient{}, false
+	}
+	engineUA = m[1]
+
+	unescaped := unescapeUpstreamUA(m[2])
+	pm := upstreamProductPattern.FindStringSubmatch(unescaped)
+	if pm == nil {
+		return engineUA, UpstreamClient{}, false
+	}
+
+	return engineUA, UpstreamClient{
+		Product: pm[1],
+		Version: pm[2],
+		OS:      pm[3],
+	}, true
+}
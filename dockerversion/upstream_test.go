@@ -0,0 +1,62 @@
+package dockerversion
+
+import "testing"
+
+func TestParseUpstreamClient(t *testing.T) {
+	cases := []struct {
+		name       string
+		ua         string
+		wantEngine string
+		want       UpstreamClient
+		wantOK     bool
+	}{
+		{
+			name:       "docker-client",
+			ua:         `docker/20.10.0 (linux) UpstreamClient(Docker-Client/20.10.0 \(linux\))`,
+			wantEngine: "docker/20.10.0 (linux)",
+			want:       UpstreamClient{Product: "Docker-Client", Version: "20.10.0", OS: "linux"},
+			wantOK:     true,
+		},
+		{
+			name:       "compose",
+			ua:         `docker/24.0.1 (linux) UpstreamClient(docker-compose/2.20.0)`,
+			wantEngine: "docker/24.0.1 (linux)",
+			want:       UpstreamClient{Product: "docker-compose", Version: "2.20.0"},
+			wantOK:     true,
+		},
+		{
+			name:       "escaped-parens-in-product",
+			ua:         `docker/24.0.1 UpstreamClient(buildx\(plugin\)/0.11.0 \(linux\))`,
+			wantEngine: "docker/24.0.1",
+			want:       UpstreamClient{Product: `buildx(plugin)`, Version: "0.11.0", OS: "linux"},
+			wantOK:     true,
+		},
+		{
+			name:       "no-upstream-client",
+			ua:         "docker/24.0.1 (linux)",
+			wantEngine: "docker/24.0.1 (linux)",
+			wantOK:     false,
+		},
+		{
+			name:       "malformed-upstream-client",
+			ua:         "docker/24.0.1 UpstreamClient(not-a-product-version)",
+			wantEngine: "docker/24.0.1",
+			wantOK:     false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			engineUA, upstream, ok := ParseUpstreamClient(tc.ua)
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tc.wantOK)
+			}
+			if engineUA != tc.wantEngine {
+				t.Errorf("engineUA = %q, want %q", engineUA, tc.wantEngine)
+			}
+			if tc.wantOK && upstream != tc.want {
+				t.Errorf("upstream = %+v, want %+v", upstream, tc.want)
+			}
+		})
+	}
+}
@@ -184,6 +184,47 @@ func (s *DockerSwarmSuite) TestUpdateSwarmAddExternalCA(c *testing.T) {
 	assert.Equal(c, info.Cluster.Spec.CAConfig.ExternalCAs[1].CACert, "cacert")
 }
 
+// TestUpdateSwarmExternalCAProtocolPlugin verifies that ExternalCA.Protocol
+// is accepted and round-tripped as an opaque driver name rather than being
+// restricted to ExternalCAProtocolCFSSL, including across a rotation that
+// swaps it back to CFSSL. Like TestUpdateSwarmAddExternalCA, it only checks
+// that the API stores and returns whatever protocol string is configured;
+// neither test exercises a daemon actually dispatching a CSR to the named
+// driver, since that dispatch path isn't in this checkout (see ExternalCA's
+// doc comment in api/types/swarm).
+func (s *DockerSwarmSuite) TestUpdateSwarmExternalCAProtocolPlugin(c *testing.T) {
+	const vaultPKIProtocol swarm.ExternalCAProtocol = "vault-pki"
+
+	ctx := testutil.GetContext(c)
+	d1 := s.AddDaemon(ctx, c, false, false)
+	d1.SwarmInit(ctx, c, swarm.InitRequest{})
+	d1.UpdateSwarm(c, func(s *swarm.Spec) {
+		s.CAConfig.ExternalCAs = []*swarm.ExternalCA{
+			{
+				Protocol: vaultPKIProtocol,
+				URL:      "https://vault.example.org/v1/pki",
+			},
+		}
+	})
+	info := d1.SwarmInfo(ctx, c)
+	assert.Equal(c, len(info.Cluster.Spec.CAConfig.ExternalCAs), 1)
+	assert.Equal(c, info.Cluster.Spec.CAConfig.ExternalCAs[0].Protocol, vaultPKIProtocol)
+
+	// Swapping the driver while a rotation is pending must not be rejected
+	// by the API, regardless of what (if anything) consumes it afterward.
+	d1.UpdateSwarm(c, func(s *swarm.Spec) {
+		s.CAConfig.ExternalCAs = []*swarm.ExternalCA{
+			{
+				Protocol: swarm.ExternalCAProtocolCFSSL,
+				URL:      "https://thishasnoca.org",
+			},
+		}
+		s.CAConfig.ForceRotate++
+	})
+	info = d1.SwarmInfo(ctx, c)
+	assert.Equal(c, info.Cluster.Spec.CAConfig.ExternalCAs[0].Protocol, swarm.ExternalCAProtocolCFSSL)
+}
+
 func (s *DockerSwarmSuite) TestAPISwarmCAHash(c *testing.T) {
 	ctx := testutil.GetContext(c)
 	d1 := s.AddDaemon(ctx, c, true, true)
@@ -739,12 +780,101 @@ func setPlacementPrefs(prefs []swarm.PlacementPreference) testdaemon.ServiceCons
 	}
 }
 
+// setWeightedSpreadPrefs configures a WeightedSpread placement preference
+// over an ordered list of label-key/weight pairs, the multi-key counterpart
+// to setPlacementPrefs' single-key Spread.
+func setWeightedSpreadPrefs(descriptors []swarm.SpreadDescriptor) testdaemon.ServiceConstructor {
+	return func(s *swarm.Service) {
+		if s.Spec.TaskTemplate.Placement == nil {
+			s.Spec.TaskTemplate.Placement = &swarm.Placement{}
+		}
+		s.Spec.TaskTemplate.Placement.Preferences = []swarm.PlacementPreference{
+			{
+				WeightedSpread: &swarm.WeightedSpreadOver{
+					SpreadDescriptors: descriptors,
+				},
+			},
+		}
+	}
+}
+
 func setGlobalMode(s *swarm.Service) {
 	s.Spec.Mode = swarm.ServiceMode{
 		Global: &swarm.GlobalService{},
 	}
 }
 
+// TestAPISwarmServiceWeightedSpreadPlacement builds a 6-node cluster with
+// distinct region/zone/rack labels, creates a 6-task service using a
+// WeightedSpread preference over all three keys, and checks that its tasks
+// land in more than one region. It does not check the zone or rack spread,
+// or that placement follows the configured weights.
+func (s *DockerSwarmSuite) TestAPISwarmServiceWeightedSpreadPlacement(c *testing.T) {
+	ctx := testutil.GetContext(c)
+	d1 := s.AddDaemon(ctx, c, true, true)
+
+	type nodeLabels struct{ region, zone, rack string }
+	topology := []nodeLabels{
+		{"east", "east-1", "rack-a"},
+		{"east", "east-1", "rack-b"},
+		{"east", "east-2", "rack-a"},
+		{"west", "west-1", "rack-a"},
+		{"west", "west-1", "rack-b"},
+		{"west", "west-2", "rack-a"},
+	}
+
+	nodes := []*daemon.Daemon{d1}
+	for i := 1; i < len(topology); i++ {
+		nodes = append(nodes, s.AddDaemon(ctx, c, true, true))
+	}
+	for i, n := range nodes {
+		labels := topology[i]
+		d1.UpdateNode(ctx, c, n.NodeID(), func(node *swarm.Node) {
+			node.Spec.Annotations.Labels = map[string]string{
+				"region": labels.region,
+				"zone":   labels.zone,
+				"rack":   labels.rack,
+			}
+		})
+	}
+
+	instances := 6
+	id := d1.CreateService(ctx, c, simpleTestService, setInstances(instances),
+		setWeightedSpreadPrefs([]swarm.SpreadDescriptor{
+			{Key: "node.labels.region", Weight: 3},
+			{Key: "node.labels.zone", Weight: 2},
+			{Key: "node.labels.rack", Weight: 1},
+		}))
+
+	var sums []int
+	checkCount := func(t *testing.T) (interface{}, string) {
+		sums = nil
+		total := 0
+		for _, n := range nodes {
+			v, msg := n.CheckActiveContainerCount(ctx)(t)
+			count, ok := v.(int)
+			if !ok {
+				return v, msg
+			}
+			sums = append(sums, count)
+			total += count
+		}
+		return total, ""
+	}
+	poll.WaitOn(c, pollCheck(c, checkCount, checker.Equals(instances)), poll.WithTimeout(defaultReconciliationTimeout))
+
+	distinctRegionsUsed := map[string]bool{}
+	for i, count := range sums {
+		if count > 0 {
+			distinctRegionsUsed[topology[i].region] = true
+		}
+	}
+	assert.Check(c, len(distinctRegionsUsed) > 1, "expected tasks spread across more than one region, got %v", sums)
+
+	apiclient := d1.NewClientT(c)
+	assert.NilError(c, apiclient.ServiceRemove(ctx, id))
+}
+
 func checkClusterHealth(t *testing.T, cl []*daemon.Daemon, managerCount, workerCount int) {
 	var totalMCount, totalWCount int
 
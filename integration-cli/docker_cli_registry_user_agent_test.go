@@ -6,47 +6,27 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/moby/moby/v2/dockerversion"
 	"github.com/moby/moby/v2/testutil"
 	"github.com/moby/moby/v2/testutil/registry"
 	"gotest.tools/v3/assert"
 )
 
-// unescapeBackslashSemicolonParens unescapes \;()
-func unescapeBackslashSemicolonParens(s string) string {
-	re := regexp.MustCompile(`\\;`)
-	ret := re.ReplaceAll([]byte(s), []byte(";"))
-
-	re = regexp.MustCompile(`\\\(`)
-	ret = re.ReplaceAll(ret, []byte("("))
-
-	re = regexp.MustCompile(`\\\)`)
-	ret = re.ReplaceAll(ret, []byte(")"))
-
-	re = regexp.MustCompile(`\\\\`)
-	ret = re.ReplaceAll(ret, []byte(`\`))
-
-	return string(ret)
-}
-
+// regexpCheckUA asserts that ua carries a well-formed UpstreamClient(...)
+// suffix (parsed via dockerversion.ParseUpstreamClient) wrapping a
+// Docker-Client identity, e.g. "docker/20.10.0 (linux) UpstreamClient(Docker-Client/1.11.0-dev (linux))".
 func regexpCheckUA(t *testing.T, ua string) {
-	re := regexp.MustCompile("(?P<dockerUA>.+) UpstreamClient(?P<upstreamUA>.+)")
-	substrArr := re.FindStringSubmatch(ua)
-
-	assert.Equal(t, len(substrArr), 3, "Expected 'UpstreamClient()' with upstream client UA")
-	dockerUA := substrArr[1]
-	upstreamUAEscaped := substrArr[2]
+	engineUA, upstream, ok := dockerversion.ParseUpstreamClient(ua)
+	assert.Assert(t, ok, "Expected 'UpstreamClient()' with upstream client UA")
 
-	// check dockerUA looks correct
+	// check engineUA looks correct
 	reDockerUA := regexp.MustCompile("^docker/[0-9A-Za-z+]")
-	bMatchDockerUA := reDockerUA.MatchString(dockerUA)
-	assert.Assert(t, bMatchDockerUA, "Docker Engine User-Agent malformed")
+	assert.Assert(t, reDockerUA.MatchString(engineUA), "Docker Engine User-Agent malformed")
 
-	// check upstreamUA looks correct
+	// check the upstream identity looks correct
 	// Expecting something like:  Docker-Client/1.11.0-dev (linux)
-	upstreamUA := unescapeBackslashSemicolonParens(upstreamUAEscaped)
-	reUpstreamUA := regexp.MustCompile(`^\(Docker-Client/[0-9A-Za-z+]`)
-	bMatchUpstreamUA := reUpstreamUA.MatchString(upstreamUA)
-	assert.Assert(t, bMatchUpstreamUA, "(Upstream) Docker Client User-Agent malformed")
+	assert.Equal(t, upstream.Product, "Docker-Client", "(Upstream) Docker Client User-Agent malformed")
+	assert.Assert(t, upstream.Version != "", "(Upstream) Docker Client User-Agent malformed")
 }
 
 // registerUserAgentHandler registers a handler for the `/v2/*` endpoint.
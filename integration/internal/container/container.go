@@ -3,6 +3,7 @@ package container
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"runtime"
 	"sync"
 	"testing"
@@ -24,6 +25,59 @@ type TestContainerConfig struct {
 	HostConfig       *container.HostConfig
 	NetworkingConfig *network.NetworkingConfig
 	Platform         *ocispec.Platform
+
+	// Platforms, set via WithPlatformMatrix, is the set of platforms
+	// RunPlatformMatrix creates and runs the container on, in parallel,
+	// instead of the single Platform above.
+	Platforms []ocispec.Platform
+
+	// ImageResolver, set via WithImageResolver, resolves the manifest
+	// descriptor for a platform. It is called once per platform in
+	// Platforms, with ref set to "<image>@<os>/<arch>[/<variant>]" so a fake
+	// resolver backing an in-memory image index can pick the right manifest
+	// without needing a real registry or content store. RunPlatformMatrix
+	// only uses whether the call returns an error: an error skips that
+	// platform, and the returned descriptor is not otherwise checked. Tests
+	// should return an error for any platform their fake index doesn't
+	// advertise.
+	ImageResolver func(ctx context.Context, ref string) (ocispec.Descriptor, error)
+}
+
+// WithPlatformMatrix configures RunPlatformMatrix to create and run the
+// container once per platform, resolving each platform's manifest through
+// WithImageResolver and asserting the image the container runs with has that
+// platform's OS, architecture and variant. Create and Run ignore this
+// option; it only has an
+// effect on RunPlatformMatrix.
+func WithPlatformMatrix(platforms ...ocispec.Platform) func(*TestContainerConfig) {
+	return func(c *TestContainerConfig) {
+		c.Platforms = platforms
+	}
+}
+
+// WithImageResolver sets the resolver RunPlatformMatrix uses to look up the
+// manifest descriptor for each platform in a WithPlatformMatrix matrix, so
+// tests can exercise image-index/platform selection against a fake index
+// instead of a real multi-arch image.
+func WithImageResolver(resolver func(ctx context.Context, ref string) (ocispec.Descriptor, error)) func(*TestContainerConfig) {
+	return func(c *TestContainerConfig) {
+		c.ImageResolver = resolver
+	}
+}
+
+// platformLabel formats p as "<os>/<arch>[/<variant>]".
+func platformLabel(p ocispec.Platform) string {
+	label := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		label += "/" + p.Variant
+	}
+	return label
+}
+
+// platformRef builds the resolver ref for a given platform, see
+// TestContainerConfig.ImageResolver.
+func platformRef(image string, p ocispec.Platform) string {
+	return fmt.Sprintf("%s@%s", image, platformLabel(p))
 }
 
 // create creates a container with the specified options
@@ -75,6 +129,107 @@ func Run(ctx context.Context, t *testing.T, client client.APIClient, ops ...func
 	return id
 }
 
+// RunPlatformMatrix creates and starts the container once per platform in
+// the WithPlatformMatrix option, in parallel, resolving each platform's
+// manifest through WithImageResolver first. A platform whose resolver call
+// errors is assumed unsupported on this node and is skipped rather than
+// failed, so cross-arch behaviors like emulation fallbacks can be exercised
+// without bespoke scaffolding in every test file. It asserts that the
+// daemon actually created the container for the right platform by
+// inspecting the image it ran with and comparing its OS/Architecture/
+// Variant against the platform that was requested. The returned map is
+// keyed by "<os>/<arch>[/<variant>]".
+func RunPlatformMatrix(ctx context.Context, t *testing.T, client client.APIClient, ops ...func(*TestContainerConfig)) map[string]string {
+	t.Helper()
+
+	config := &TestContainerConfig{}
+	for _, op := range ops {
+		op(config)
+	}
+	assert.Assert(t, len(config.Platforms) > 0, "RunPlatformMatrix requires WithPlatformMatrix")
+	assert.Assert(t, config.ImageResolver != nil, "RunPlatformMatrix requires WithImageResolver")
+
+	image := "busybox"
+	if config.Config != nil && config.Config.Image != "" {
+		image = config.Config.Image
+	}
+
+	type result struct {
+		label string
+		id    string
+		err   error
+		skip  string
+	}
+
+	results := make(chan result, len(config.Platforms))
+	var wg sync.WaitGroup
+	for _, p := range config.Platforms {
+		p := p
+		label := platformLabel(p)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := config.ImageResolver(ctx, platformRef(image, p))
+			if err != nil {
+				results <- result{label: label, skip: err.Error()}
+				return
+			}
+
+			platformOps := append(append([]func(*TestContainerConfig){}, ops...), func(c *TestContainerConfig) {
+				c.Platform = &p
+			})
+			id, err := create(ctx, t, client, platformOps...)
+			if err != nil {
+				results <- result{label: label, err: err}
+				return
+			}
+			if err := client.ContainerStart(ctx, id.ID, types.ContainerStartOptions{}); err != nil {
+				results <- result{label: label, err: err}
+				return
+			}
+
+			inspect, err := client.ContainerInspect(ctx, id.ID)
+			if err != nil {
+				results <- result{label: label, err: err}
+				return
+			}
+
+			// inspect.Image is the image's config digest, not the
+			// manifest digest desc carries (that's what identifies the
+			// entry in the index, before the manifest is resolved down
+			// to a platform-specific image), so the two are never
+			// comparable. Inspect the image instead and check it's
+			// actually for the platform RunPlatformMatrix asked for.
+			imgInspect, _, err := client.ImageInspectWithRaw(ctx, inspect.Image)
+			if err != nil {
+				results <- result{label: label, err: err}
+				return
+			}
+			if imgInspect.Os != p.OS || imgInspect.Architecture != p.Architecture || imgInspect.Variant != p.Variant {
+				results <- result{label: label, err: fmt.Errorf("container %s on platform %s runs image %s for platform %s/%s/%s, want %s", id.ID, label, inspect.Image, imgInspect.Os, imgInspect.Architecture, imgInspect.Variant, label)}
+				return
+			}
+
+			results <- result{label: label, id: id.ID}
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+
+	ids := map[string]string{}
+	for r := range results {
+		if r.skip != "" {
+			t.Logf("skipping platform %s: %s", r.label, r.skip)
+			continue
+		}
+		assert.NilError(t, r.err, "platform %s", r.label)
+		ids[r.label] = r.id
+	}
+	return ids
+}
+
 type streams struct {
 	stdout, stderr bytes.Buffer
 }
@@ -3,24 +3,113 @@ package layer
 import (
 	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
 	"errors"
+	"fmt"
 	"io"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 // DigestSHA256EmptyTar is the canonical sha256 digest of empty tar file -
 // (1024 NULL bytes)
 const DigestSHA256EmptyTar DiffID = "sha256:5f70bf18a086007016e948b04aed3b82103a36bea41755b6cddfaf10ace3c6ef"
 
-type emptyLayer struct{}
+// BlobDigest is the digest of a layer's on-the-wire representation, i.e.
+// what a registry blob (and a manifest layer descriptor) is addressed by.
+// Unlike DiffID, which always identifies the uncompressed tar content, a
+// BlobDigest varies with compression: the gzip and zstd encodings of the
+// same tar have different BlobDigests but the same DiffID. The two only
+// coincide for an uncompressed layer, where the blob is the tar itself.
+type BlobDigest string
 
-// EmptyLayer is a layer that corresponds to empty tar.
-var EmptyLayer = &emptyLayer{}
+// DigestSHA256EmptyTarGzip is the blob digest of the empty tar
+// gzip-compressed with this package's encoder settings. gzip's header
+// carries an OS byte and an mtime that differ between writers, so there is
+// no single cross-ecosystem canonical value the way there is for the raw
+// tar; it's computed once in init() from the bytes compressGzip actually
+// produces instead of hardcoded, the same way DigestSHA256EmptyTarZstd is.
+var DigestSHA256EmptyTarGzip BlobDigest
 
-func (el *emptyLayer) TarStream() (io.ReadCloser, error) {
+// DigestSHA256EmptyTarZstd is the blob digest of the empty tar
+// zstd-compressed with this package's encoder settings. There is no single
+// cross-ecosystem canonical value for zstd the way there is for the raw
+// tar, so it's computed once in init() from emptyTarBytes instead of
+// hardcoded.
+var DigestSHA256EmptyTarZstd BlobDigest
+
+var emptyTarBytes []byte
+
+func init() {
 	buf := new(bytes.Buffer)
 	tarWriter := tar.NewWriter(buf)
 	_ = tarWriter.Close()
-	return io.NopCloser(buf), nil
+	emptyTarBytes = buf.Bytes()
+
+	gzipBytes, err := compressGzip(emptyTarBytes)
+	if err != nil {
+		panic(fmt.Errorf("layer: failed to precompute empty gzip layer: %w", err))
+	}
+	DigestSHA256EmptyTarGzip = BlobDigest(fmt.Sprintf("sha256:%x", sha256.Sum256(gzipBytes)))
+	gzipEmptyLayer = &compressedEmptyLayer{blobDigest: DigestSHA256EmptyTarGzip, bytes: gzipBytes}
+
+	zstdBytes, err := compressZstd(emptyTarBytes)
+	if err != nil {
+		panic(fmt.Errorf("layer: failed to precompute empty zstd layer: %w", err))
+	}
+	DigestSHA256EmptyTarZstd = BlobDigest(fmt.Sprintf("sha256:%x", sha256.Sum256(zstdBytes)))
+	zstdEmptyLayer = &compressedEmptyLayer{blobDigest: DigestSHA256EmptyTarZstd, bytes: zstdBytes}
+
+	emptyBlobDigests[DigestSHA256EmptyTarGzip] = struct{}{}
+	emptyBlobDigests[DigestSHA256EmptyTarZstd] = struct{}{}
+}
+
+func compressZstd(p []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w, err := zstd.NewWriter(buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(p); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func compressGzip(p []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(p); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// emptyBlobDigests are the blob digests of the empty-tar variants
+// IsEmptyBlobDigest and EmptyLayerFor recognize: the raw tar (whose blob
+// digest equals its DiffID, since it isn't compressed), and the compressed
+// forms in common use for OCI/Docker image layers. The compressed entries
+// are added by init().
+var emptyBlobDigests = map[BlobDigest]struct{}{
+	BlobDigest(DigestSHA256EmptyTar): {},
+}
+
+type emptyLayer struct{}
+
+// EmptyLayer is a layer that corresponds to an uncompressed empty tar.
+var EmptyLayer = &emptyLayer{}
+
+func (el *emptyLayer) TarStream() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(emptyTarBytes)), nil
 }
 
 func (el *emptyLayer) TarStreamFrom(p ChainID) (io.ReadCloser, error) {
@@ -54,7 +143,84 @@ func (el *emptyLayer) Metadata() (map[string]string, error) {
 	return make(map[string]string), nil
 }
 
-// IsEmpty returns true if the layer is an EmptyLayer
+// compressedEmptyLayer is an emptyLayer variant whose TarStream yields the
+// empty tar already compressed, so pushing a layer that's already known to
+// be empty under a compressed digest doesn't require re-compressing it, and
+// lets the distribution push path dedupe against whichever empty-blob
+// digest the registry already has.
+//
+// DiffID is always DigestSHA256EmptyTar: DiffID identifies the uncompressed
+// tar content, which is the same empty tar regardless of how it's
+// compressed on the wire, and ChainID for a base layer is just its DiffID.
+// blobDigest is the digest of the compressed bytes themselves (what the
+// registry blob is addressed by), kept separate so it never gets confused
+// for the DiffID and corrupts ChainID for any layer stacked on top.
+type compressedEmptyLayer struct {
+	blobDigest BlobDigest
+	bytes      []byte
+}
+
+func (el *compressedEmptyLayer) TarStream() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(el.bytes)), nil
+}
+
+func (el *compressedEmptyLayer) TarStreamFrom(p ChainID) (io.ReadCloser, error) {
+	if p == "" {
+		return el.TarStream()
+	}
+	return nil, errors.New("can't get parent tar stream of an empty layer")
+}
+
+// BlobDigest returns the digest of the compressed bytes TarStream produces,
+// i.e. what the registry blob for this variant is addressed by. The
+// distribution push path uses this (not DiffID) to dedupe against an
+// already-existing compressed empty-layer blob.
+func (el *compressedEmptyLayer) BlobDigest() BlobDigest { return el.blobDigest }
+
+func (el *compressedEmptyLayer) ChainID() ChainID { return ChainID(DigestSHA256EmptyTar) }
+func (el *compressedEmptyLayer) DiffID() DiffID   { return DigestSHA256EmptyTar }
+func (el *compressedEmptyLayer) Parent() Layer    { return nil }
+func (el *compressedEmptyLayer) Size() int64      { return 0 }
+func (el *compressedEmptyLayer) DiffSize() int64  { return 0 }
+func (el *compressedEmptyLayer) Metadata() (map[string]string, error) {
+	return make(map[string]string), nil
+}
+
+var (
+	gzipEmptyLayer *compressedEmptyLayer
+	zstdEmptyLayer *compressedEmptyLayer
+)
+
+// EmptyLayerFor returns the Layer whose TarStream produces an empty tar
+// compressed for mediaType, so the distribution push path can dedupe
+// against (and avoid re-compressing into) whichever empty-blob digest the
+// registry already has. It returns the plain, uncompressed EmptyLayer for
+// any mediaType it doesn't recognize as gzip or zstd.
+func EmptyLayerFor(mediaType string) Layer {
+	switch mediaType {
+	case "application/vnd.docker.image.rootfs.diff.tar.gzip",
+		"application/vnd.oci.image.layer.v1.tar+gzip":
+		return gzipEmptyLayer
+	case "application/vnd.oci.image.layer.v1.tar+zstd":
+		return zstdEmptyLayer
+	default:
+		return EmptyLayer
+	}
+}
+
+// IsEmpty returns true if diffID is the empty layer's DiffID. Unlike
+// BlobDigest, DiffID doesn't vary with compression (see compressedEmptyLayer's
+// doc comment), so there's exactly one empty DiffID regardless of how the
+// layer is compressed on the wire; use IsEmptyBlobDigest to recognize a
+// compressed blob digest instead.
 func IsEmpty(diffID DiffID) bool {
 	return diffID == DigestSHA256EmptyTar
 }
+
+// IsEmptyBlobDigest returns true if digest identifies any known
+// empty-layer blob: the uncompressed empty tar (whose blob digest equals
+// its DiffID), or one of its gzip/zstd-compressed forms.
+func IsEmptyBlobDigest(digest BlobDigest) bool {
+	_, ok := emptyBlobDigests[digest]
+	return ok
+}